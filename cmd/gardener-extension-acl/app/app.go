@@ -28,12 +28,14 @@ import (
 	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	componentbaseconfigv1alpha1 "k8s.io/component-base/config/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	aclinstall "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/install"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/controller"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/controller/healthcheck"
 )
@@ -80,6 +82,9 @@ func (o *Options) run(ctx context.Context) error {
 		},
 	}
 
+	ctrlConfig := o.extensionOptions.Completed()
+	ctrlConfig.Apply(&controller.DefaultAddOptions.ExtensionConfig)
+
 	// Only cache services that are needed to check for ProxyProto usage
 	mgrOpts.Cache.ByObject = map[client.Object]cache.ByObject{
 		&corev1.Service{}: {
@@ -88,6 +93,14 @@ func (o *Options) run(ctx context.Context) error {
 			}.AsSelector(),
 		},
 	}
+	// Only cache the ConfigMap containing the CIDR sets, which is watched to
+	// reconcile the extensions referencing them on changes
+	if cidrSets := controller.DefaultAddOptions.ExtensionConfig.CIDRSetsConfigMap; cidrSets.Name != "" {
+		mgrOpts.Cache.ByObject[&corev1.ConfigMap{}] = cache.ByObject{
+			Namespaces: map[string]cache.Config{cidrSets.Namespace: {}},
+			Field:      fields.OneTermEqualSelector(metav1.ObjectNameField, cidrSets.Name),
+		}
+	}
 
 	mgr, err := manager.New(o.restOptions.Completed().Config, mgrOpts)
 	if err != nil {
@@ -98,6 +111,10 @@ func (o *Options) run(ctx context.Context) error {
 		return fmt.Errorf("could not update manager scheme: %s", err)
 	}
 
+	if err := aclinstall.AddToScheme(mgr.GetScheme()); err != nil {
+		return fmt.Errorf("could not update manager scheme: %s", err)
+	}
+
 	if err := istionetworkv1alpha3.AddToScheme(mgr.GetScheme()); err != nil {
 		return fmt.Errorf("could not update manager scheme: %s", err)
 	}
@@ -105,9 +122,7 @@ func (o *Options) run(ctx context.Context) error {
 		return fmt.Errorf("could not update manager scheme: %s", err)
 	}
 
-	ctrlConfig := o.extensionOptions.Completed()
 	ctrlConfig.ApplyHealthCheckConfig(&healthcheck.DefaultAddOptions.HealthCheckConfig)
-	ctrlConfig.Apply(&controller.DefaultAddOptions.ExtensionConfig)
 
 	o.controllerOptions.Completed().Apply(&controller.DefaultAddOptions.ControllerOptions)
 	o.healthOptions.Completed().Apply(&healthcheck.DefaultAddOptions.Controller)
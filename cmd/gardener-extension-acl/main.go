@@ -17,6 +17,7 @@ package main
 
 import (
 	"os"
+	_ "time/tzdata" // the time zones of rule schedules must be available in distroless images
 
 	"github.com/gardener/gardener/cmd/utils"
 	"github.com/gardener/gardener/pkg/logger"
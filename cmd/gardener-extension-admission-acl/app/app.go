@@ -27,7 +27,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	admissioncmd "github.com/stackitcloud/gardener-extension-acl/pkg/admission/cmd"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/admission/mutator"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/admission/validator"
+	aclinstall "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/install"
 )
 
 // ExtensionName is the name of the extension.
@@ -96,6 +98,9 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("error completing options: %w", err)
 			}
 			validator.DefaultAddOptions.MaxAllowedCIDRs = admissionOptions.Completed().MaxAllowedCIDRs
+			validator.DefaultAddOptions.AdditionalAllowedCIDRs = admissionOptions.Completed().AdditionalAllowedCIDRs
+			validator.DefaultAddOptions.Policy = admissionOptions.Completed().Policy
+			mutator.DefaultAddOptions.DefaultACLConfig = admissionOptions.Completed().DefaultACLConfig
 
 			util.ApplyClientConnectionConfigurationToRESTConfig(&componentbaseconfigv1alpha1.ClientConnectionConfiguration{
 				QPS:   100.0,
@@ -133,6 +138,7 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 			}
 
 			install.Install(mgr.GetScheme())
+			aclinstall.Install(mgr.GetScheme())
 
 			var sourceCluster cluster.Cluster
 			if sourceClusterConfig != nil {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	_ "time/tzdata" // the time zones of rule schedules must be available in distroless images
 
 	"github.com/gardener/gardener/pkg/logger"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -0,0 +1,16 @@
+#!/usr/bin/env bash
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+REPO_ROOT="$(realpath "$(dirname "$0")/..")"
+CODEGEN_PKG="$(go list -m -f '{{.Dir}}' k8s.io/code-generator)"
+
+source "${CODEGEN_PKG}/kube_codegen.sh"
+
+echo "> Generating deepcopy, defaulting and conversion functions"
+
+kube::codegen::gen_helpers \
+  --boilerplate /dev/null \
+  "${REPO_ROOT}/pkg/apis"
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/validation"
 	controllerconfig "github.com/stackitcloud/gardener-extension-acl/pkg/controller/config"
 )
 
@@ -10,16 +12,44 @@ import (
 type AdmissionOptions struct {
 	// MaxAllowedCIDRs is the maximum number of allowed CIDRs per cluster
 	MaxAllowedCIDRs int
+	// AdditionalAllowedCIDRs are the CIDRs the extension allows for every cluster
+	AdditionalAllowedCIDRs []string
+	// Policy is the policy enforced for the rules of all clusters
+	Policy validation.Policy
+	// DefaultACLConfig is the providerConfig injected into new clusters of
+	// opted-in projects without their own defaults
+	DefaultACLConfig string
 }
 
 // AddFlags implements Flagger.AddFlags.
 func (a *AdmissionOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&a.MaxAllowedCIDRs, "maxAllowedCIDRs", 50, "maximum number of allowed CIDRs per cluster")
+	fs.StringSliceVar(
+		&a.AdditionalAllowedCIDRs,
+		"additional-allowed-cidrs",
+		nil,
+		"List of CIDRs the extension allows for every cluster, used to warn about redundant rules, e.g. '192.168.1.40/32,10.250.0.0/16'",
+	)
+	fs.IntVar(&a.Policy.MinPrefixLengthIPv4, "policy-min-prefix-length-ipv4", 0, "minimum prefix length of allowed IPv4 CIDRs, 0 disables the check")
+	fs.IntVar(&a.Policy.MinPrefixLengthIPv6, "policy-min-prefix-length-ipv6", 0, "minimum prefix length of allowed IPv6 CIDRs, 0 disables the check")
+	fs.BoolVar(&a.Policy.ForbidAllAddresses, "policy-forbid-all-addresses", false, "forbid allowing all addresses with 0.0.0.0/0 or ::/0")
+	fs.StringSliceVar(
+		&a.Policy.ForbiddenCIDRs,
+		"policy-forbidden-cidrs",
+		nil,
+		"List of ranges which must not be allowed, e.g. shared NAT gateways '192.0.2.0/28'",
+	)
+	fs.StringVar(
+		&a.DefaultACLConfig,
+		"default-acl-config",
+		"",
+		"ACLConfig (JSON or YAML) injected into new clusters of opted-in projects without an acl-defaults ConfigMap",
+	)
 }
 
 // Complete implements Completer.Complete.
 func (a *AdmissionOptions) Complete() error {
-	return nil
+	return validation.ValidatePolicy(&a.Policy, field.NewPath("policy")).ToAggregate()
 }
 
 // Completed returns the completed Config. Only call this if `Complete` was successful.
@@ -30,4 +60,5 @@ func (a *AdmissionOptions) Completed() *AdmissionOptions {
 // Apply sets the values of this Config in the given config.ControllerConfiguration.
 func (a *AdmissionOptions) Apply(config *controllerconfig.Config) {
 	config.MaxAllowedCIDRs = a.MaxAllowedCIDRs
+	config.AdditionalAllowedCIDRs = a.AdditionalAllowedCIDRs
 }
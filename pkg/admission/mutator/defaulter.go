@@ -0,0 +1,154 @@
+package mutator
+
+import (
+	"context"
+	"fmt"
+
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/pkg/apis/core"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/admission/validator"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/controller"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/helper"
+)
+
+const (
+	// LabelInjectDefaults is the label on a project or its namespace opting in
+	// to the injection of the acl extension into new shoots of the project.
+	LabelInjectDefaults = "acl.extensions.gardener.cloud/inject-defaults"
+	// AnnotationInjectedFrom is the annotation on a shoot recording where its
+	// injected acl extension came from.
+	AnnotationInjectedFrom = "acl.extensions.gardener.cloud/injected-from"
+	// DefaultsConfigMapName is the name of the ConfigMap in the project namespace
+	// containing the providerConfig injected into new shoots.
+	DefaultsConfigMapName = "acl-defaults"
+	// DefaultsConfigMapKey is the key of the providerConfig in the ConfigMap.
+	DefaultsConfigMapKey = "providerConfig"
+	// LabelExtension is the label Gardener adds to shoots with the acl
+	// extension. The object selectors of the other webhooks match it, but as
+	// Gardener adds it before calling the webhooks, it is added to shoots with
+	// an injected extension by the defaulter.
+	LabelExtension = "extensions.extensions.gardener.cloud/" + controller.Type
+
+	injectedFromOperatorDefault = "operator-default"
+)
+
+// DefaultAddOptions are the default options to apply when adding the webhook to the manager.
+var DefaultAddOptions = AddOptions{}
+
+// AddOptions are options to apply when adding the webhook to the manager.
+type AddOptions struct {
+	// DefaultACLConfig is the providerConfig injected into new shoots of
+	// opted-in projects without a DefaultsConfigMapName ConfigMap. If it is
+	// empty, nothing is injected for these projects.
+	DefaultACLConfig string
+}
+
+// NewShootDefaulter returns a new instance of a shootDefaulter. The decoder is
+// used to decode the default providerConfig, the encoder to write it to the
+// shoot and the client to read the project and the ConfigMap with the defaults.
+func NewShootDefaulter(decoder runtime.Decoder, encoder runtime.Encoder, c client.Reader) extensionswebhook.Mutator {
+	return &shootDefaulter{decoder: decoder, encoder: encoder, client: c}
+}
+
+type shootDefaulter struct {
+	decoder runtime.Decoder
+	encoder runtime.Encoder
+	client  client.Reader
+}
+
+// Mutate injects the acl extension into the given shoot on creation, if its
+// project is labeled with LabelInjectDefaults and the shoot does not configure
+// the acl extension itself.
+func (s *shootDefaulter) Mutate(ctx context.Context, new, old client.Object) error {
+	shoot, ok := new.(*core.Shoot)
+	if !ok {
+		return fmt.Errorf("wrong object type %T", new)
+	}
+	if old != nil {
+		return nil
+	}
+
+	for _, ext := range shoot.Spec.Extensions {
+		if ext.Type == controller.Type {
+			return nil
+		}
+	}
+
+	ns, project, err := helper.GetProjectForNamespace(ctx, s.client, shoot.Namespace)
+	if err != nil {
+		return err
+	}
+	if !helper.HasProjectLabel(ns, project, LabelInjectDefaults) {
+		return nil
+	}
+
+	rawConfig, injectedFrom, err := s.getDefaults(ctx, shoot.Namespace)
+	if err != nil || rawConfig == "" {
+		return err
+	}
+
+	extensionSpec, err := aclhelper.DecodeACLConfig(s.decoder, &runtime.RawExtension{Raw: []byte(rawConfig)})
+	if err != nil {
+		return fmt.Errorf("error decoding ACL defaults of %s: %w", injectedFrom, err)
+	}
+	aclhelper.NormalizeACLConfig(extensionSpec)
+	// the defaults must comply with the limit and the policy of the project
+	// like the rules of the shoot itself
+	errs, err := validator.ValidateDefaultACLConfig(ns, extensionSpec, field.NewPath("providerConfig"))
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid ACL defaults of %s: %w", injectedFrom, errs.ToAggregate())
+	}
+
+	return s.injectExtension(shoot, extensionSpec, injectedFrom)
+}
+
+// getDefaults returns the providerConfig to inject into the shoots of the given
+// namespace and where it came from: the DefaultsConfigMapName ConfigMap of the
+// namespace or the operator-level DefaultACLConfig.
+func (s *shootDefaulter) getDefaults(ctx context.Context, namespace string) (string, string, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := s.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: DefaultsConfigMapName}, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return DefaultAddOptions.DefaultACLConfig, injectedFromOperatorDefault, nil
+		}
+		return "", "", fmt.Errorf("error getting ConfigMap %s/%s: %w", namespace, DefaultsConfigMapName, err)
+	}
+
+	rawConfig, ok := configMap.Data[DefaultsConfigMapKey]
+	if !ok {
+		return "", "", fmt.Errorf("ConfigMap %s/%s does not contain the key %s", namespace, DefaultsConfigMapName, DefaultsConfigMapKey)
+	}
+	return rawConfig, "configmap/" + DefaultsConfigMapName, nil
+}
+
+func (s *shootDefaulter) injectExtension(shoot *core.Shoot, extensionSpec *acl.ACLConfig, injectedFrom string) error {
+	raw, err := runtime.Encode(s.encoder, extensionSpec)
+	if err != nil {
+		return fmt.Errorf("error encoding ACL extension spec: %w", err)
+	}
+
+	shoot.Spec.Extensions = append(shoot.Spec.Extensions, core.Extension{
+		Type:           controller.Type,
+		ProviderConfig: &runtime.RawExtension{Raw: raw},
+	})
+	if shoot.Annotations == nil {
+		shoot.Annotations = map[string]string{}
+	}
+	shoot.Annotations[AnnotationInjectedFrom] = injectedFrom
+	if shoot.Labels == nil {
+		shoot.Labels = map[string]string{}
+	}
+	shoot.Labels[LabelExtension] = "true"
+	return nil
+}
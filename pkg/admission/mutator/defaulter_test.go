@@ -0,0 +1,157 @@
+package mutator_test
+
+import (
+	"context"
+
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/pkg/apis/core"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/admission/mutator"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/admission/validator"
+	aclinstall "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/install"
+	aclv1alpha1 "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/v1alpha1"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/validation"
+)
+
+var _ = Describe("Shoot defaulter", func() {
+	Describe("#Mutate", func() {
+		var (
+			namespace *corev1.Namespace
+			project   *gardencorev1beta1.Project
+			configMap *corev1.ConfigMap
+			shoot     *core.Shoot
+
+			ctx = context.Background()
+		)
+
+		BeforeEach(func() {
+			namespace = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "garden-dev",
+					Labels: map[string]string{"project.gardener.cloud/name": "dev"},
+				},
+			}
+			project = &gardencorev1beta1.Project{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "dev",
+					Labels: map[string]string{mutator.LabelInjectDefaults: "true"},
+				},
+			}
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mutator.DefaultsConfigMapName,
+					Namespace: "garden-dev",
+				},
+				Data: map[string]string{
+					mutator.DefaultsConfigMapKey: "rule:\n  action: allow\n  cidrs:\n  - 10.250.1.1/16\n",
+				},
+			}
+
+			mutator.DefaultAddOptions.DefaultACLConfig = `{"rule":{"action":"ALLOW","cidrs":["192.0.2.0/24"]}}`
+			DeferCleanup(func() { mutator.DefaultAddOptions.DefaultACLConfig = "" })
+
+			shoot = &core.Shoot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "garden-dev",
+				},
+			}
+		})
+
+		newDefaulter := func(objects ...client.Object) extensionswebhook.Mutator {
+			scheme := runtime.NewScheme()
+			aclinstall.Install(scheme)
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+			fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			codecs := serializer.NewCodecFactory(scheme, serializer.EnableStrict)
+			info, _ := runtime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), runtime.ContentTypeJSON)
+			return mutator.NewShootDefaulter(codecs.UniversalDecoder(), codecs.EncoderForVersion(info.Serializer, aclv1alpha1.SchemeGroupVersion), fakeClient)
+		}
+
+		It("should inject the normalized rules of the ConfigMap", func() {
+			Expect(newDefaulter(namespace, project, configMap).Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot.Spec.Extensions).To(HaveLen(1))
+			Expect(shoot.Spec.Extensions[0].Type).To(Equal("acl"))
+			Expect(shoot.Spec.Extensions[0].ProviderConfig.Raw).To(MatchJSON(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"ACLConfig","rule":{"action":"ALLOW","cidrs":["10.250.0.0/16"],"type":"remote_ip"}}`))
+			Expect(shoot.Annotations).To(HaveKeyWithValue(mutator.AnnotationInjectedFrom, "configmap/acl-defaults"))
+			Expect(shoot.Labels).To(HaveKeyWithValue("extensions.extensions.gardener.cloud/acl", "true"))
+		})
+
+		It("should inject the operator default without ConfigMap", func() {
+			Expect(newDefaulter(namespace, project).Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot.Spec.Extensions).To(HaveLen(1))
+			Expect(shoot.Spec.Extensions[0].ProviderConfig.Raw).To(MatchJSON(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"ACLConfig","rule":{"action":"ALLOW","cidrs":["192.0.2.0/24"],"type":"remote_ip"}}`))
+			Expect(shoot.Annotations).To(HaveKeyWithValue(mutator.AnnotationInjectedFrom, "operator-default"))
+		})
+
+		It("should not inject anything without ConfigMap and operator default", func() {
+			mutator.DefaultAddOptions.DefaultACLConfig = ""
+			Expect(newDefaulter(namespace, project).Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot.Spec.Extensions).To(BeEmpty())
+			Expect(shoot.Annotations).To(BeEmpty())
+		})
+
+		It("should not inject anything if the project did not opt in", func() {
+			project.Labels = nil
+			Expect(newDefaulter(namespace, project, configMap).Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot.Spec.Extensions).To(BeEmpty())
+		})
+
+		It("should not change a shoot configuring the acl extension itself", func() {
+			shoot.Spec.Extensions = []core.Extension{{Type: "acl", Disabled: ptr.To(true)}}
+			expected := shoot.DeepCopy()
+			Expect(newDefaulter(namespace, project, configMap).Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot).To(Equal(expected))
+		})
+
+		It("should not change existing shoots", func() {
+			oldShoot := shoot.DeepCopy()
+			Expect(newDefaulter(namespace, project, configMap).Mutate(ctx, shoot, oldShoot)).To(Succeed())
+			Expect(shoot.Spec.Extensions).To(BeEmpty())
+		})
+
+		It("should return err if the ConfigMap contains invalid rules", func() {
+			configMap.Data[mutator.DefaultsConfigMapKey] = `{"rule":{"action":"banana","cidrs":["10.250.0.0/16"]}}`
+			Expect(newDefaulter(namespace, project, configMap).Mutate(ctx, shoot, nil)).To(MatchError(ContainSubstring("invalid ACL defaults of configmap/acl-defaults")))
+		})
+
+		Context("Limits and policy of the project", func() {
+			BeforeEach(func() {
+				validator.DefaultAddOptions.MaxAllowedCIDRs = 5
+				validator.DefaultAddOptions.Policy = validation.Policy{MinPrefixLengthIPv4: 16}
+				DeferCleanup(func() { validator.DefaultAddOptions = validator.AddOptions{} })
+			})
+
+			It("should return err if the defaults exceed the maximum number of CIDRs of the namespace", func() {
+				namespace.Annotations = map[string]string{validator.AnnotationMaxAllowedCIDRs: "1"}
+				configMap.Data[mutator.DefaultsConfigMapKey] = `{"rule":{"action":"ALLOW","cidrs":["10.250.0.0/16","10.251.0.0/16"]}}`
+				Expect(newDefaulter(namespace, project, configMap).Mutate(ctx, shoot, nil)).To(MatchError(ContainSubstring("providerConfig.rule.cidrs")))
+				Expect(shoot.Spec.Extensions).To(BeEmpty())
+			})
+
+			It("should return err if the defaults violate the policy", func() {
+				configMap.Data[mutator.DefaultsConfigMapKey] = `{"rule":{"action":"ALLOW","cidrs":["10.0.0.0/8"]}}`
+				Expect(newDefaulter(namespace, project, configMap).Mutate(ctx, shoot, nil)).To(MatchError(ContainSubstring("the policy requires a prefix length of at least 16")))
+				Expect(shoot.Spec.Extensions).To(BeEmpty())
+			})
+
+			It("should inject defaults violating the policy if the namespace is exempt", func() {
+				namespace.Labels[validator.LabelPolicyExempt] = "true"
+				configMap.Data[mutator.DefaultsConfigMapKey] = `{"rule":{"action":"ALLOW","cidrs":["10.0.0.0/8"]}}`
+				Expect(newDefaulter(namespace, project, configMap).Mutate(ctx, shoot, nil)).To(Succeed())
+				Expect(shoot.Spec.Extensions).To(HaveLen(1))
+			})
+		})
+	})
+})
@@ -0,0 +1,75 @@
+package mutator
+
+import (
+	"context"
+	"fmt"
+
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/pkg/apis/core"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/controller"
+)
+
+// NewShootMutator returns a new instance of a shootMutator. The decoder is used
+// to decode the providerConfig of the acl extension, the encoder to write the
+// normalized providerConfig back to the shoot.
+func NewShootMutator(decoder runtime.Decoder, encoder runtime.Encoder) extensionswebhook.Mutator {
+	return &shootMutator{decoder: decoder, encoder: encoder}
+}
+
+type shootMutator struct {
+	decoder runtime.Decoder
+	encoder runtime.Encoder
+}
+
+// Mutate normalizes the providerConfig of the acl extension of the given shoot,
+// so the stored rules equal the enforced ones.
+func (s *shootMutator) Mutate(ctx context.Context, new, _ client.Object) error {
+	shoot, ok := new.(*core.Shoot)
+	if !ok {
+		return fmt.Errorf("wrong object type %T", new)
+	}
+	return s.mutateShoot(ctx, shoot)
+}
+
+func (s *shootMutator) mutateShoot(_ context.Context, shoot *core.Shoot) error {
+	aclExtension := s.findExtension(shoot)
+	if aclExtension == nil || aclExtension.ProviderConfig == nil {
+		return nil
+	}
+
+	if aclExtension.Disabled != nil && *aclExtension.Disabled {
+		return nil
+	}
+
+	extensionSpec, err := aclhelper.DecodeACLConfig(s.decoder, aclExtension.ProviderConfig)
+	if err != nil {
+		return nil //nolint:nilerr // invalid configs are left untouched and rejected by the validator
+	}
+
+	normalized := extensionSpec.DeepCopy()
+	aclhelper.NormalizeACLConfig(normalized)
+	if equality.Semantic.DeepEqual(extensionSpec, normalized) {
+		return nil
+	}
+
+	raw, err := runtime.Encode(s.encoder, normalized)
+	if err != nil {
+		return fmt.Errorf("error encoding ACL extension spec: %w", err)
+	}
+	aclExtension.ProviderConfig = &runtime.RawExtension{Raw: raw}
+	return nil
+}
+
+func (s *shootMutator) findExtension(shoot *core.Shoot) *core.Extension {
+	for i, ext := range shoot.Spec.Extensions {
+		if ext.Type == controller.Type {
+			return &shoot.Spec.Extensions[i]
+		}
+	}
+	return nil
+}
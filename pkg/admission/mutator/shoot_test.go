@@ -0,0 +1,100 @@
+package mutator_test
+
+import (
+	"context"
+
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/pkg/apis/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/admission/mutator"
+	aclinstall "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/install"
+	aclv1alpha1 "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/v1alpha1"
+)
+
+var _ = Describe("Shoot mutator", func() {
+	Describe("#Mutate", func() {
+		var (
+			shootMutator extensionswebhook.Mutator
+
+			shoot *core.Shoot
+
+			ctx = context.Background()
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			aclinstall.Install(scheme)
+			codecs := serializer.NewCodecFactory(scheme, serializer.EnableStrict)
+			info, _ := runtime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), runtime.ContentTypeJSON)
+			shootMutator = mutator.NewShootMutator(codecs.UniversalDecoder(), codecs.EncoderForVersion(info.Serializer, aclv1alpha1.SchemeGroupVersion))
+
+			shoot = &core.Shoot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "garden-dev",
+				},
+				Spec: core.ShootSpec{
+					Extensions: []core.Extension{
+						{
+							Type:           "acl",
+							ProviderConfig: &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24","10.250.0.0/16"],"type":"remote_ip"}}`)},
+						},
+					},
+				},
+			}
+		})
+
+		It("should not change a normalized provider config", func() {
+			expected := shoot.DeepCopy()
+			Expect(shootMutator.Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot).To(Equal(expected))
+		})
+
+		It("should canonicalize the casing of action and type", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"allow","cidrs":["10.250.0.0/16"],"type":"Remote_IP"}}`)}
+			Expect(shootMutator.Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot.Spec.Extensions[0].ProviderConfig.Raw).To(MatchJSON(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"ACLConfig","rule":{"action":"ALLOW","cidrs":["10.250.0.0/16"],"type":"remote_ip"}}`))
+		})
+
+		It("should convert host addresses to network addresses and dedupe and sort the CIDRs", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rules":[{"action":"ALLOW","cidrs":["10.250.1.1/16","1.2.3.4/24","10.250.0.0/16"],"type":"remote_ip"}],"apiServer":{"rule":{"action":"ALLOW","cidrs":["2001:db8::1/32"],"type":"remote_ip"}}}`)}
+			Expect(shootMutator.Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot.Spec.Extensions[0].ProviderConfig.Raw).To(MatchJSON(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"ACLConfig","rules":[{"action":"ALLOW","cidrs":["1.2.3.0/24","10.250.0.0/16"],"type":"remote_ip"}],"apiServer":{"rule":{"action":"ALLOW","cidrs":["2001:db8::/32"],"type":"remote_ip"}}}`))
+		})
+
+		It("should not add empty cidrs to rules getting their CIDRs from other sources", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","hosts":["example.com"]}}`)}
+			Expect(shootMutator.Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot.Spec.Extensions[0].ProviderConfig.Raw).To(MatchJSON(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"ACLConfig","rule":{"action":"ALLOW","hosts":["example.com"],"type":"remote_ip"}}`))
+		})
+
+		It("should not change an invalid provider config", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"allow","cidr":["10.250.1.1/16"]}}`)}
+			expected := shoot.DeepCopy()
+			Expect(shootMutator.Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot).To(Equal(expected))
+		})
+
+		It("should not change a disabled extension", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"allow","cidrs":["10.250.1.1/16"]}}`)}
+			shoot.Spec.Extensions[0].Disabled = ptr.To(true)
+			expected := shoot.DeepCopy()
+			Expect(shootMutator.Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot).To(Equal(expected))
+		})
+
+		It("should ignore shoots without the acl extension", func() {
+			shoot.Spec.Extensions[0].Type = "foo"
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"allow"}}`)}
+			expected := shoot.DeepCopy()
+			Expect(shootMutator.Mutate(ctx, shoot, nil)).To(Succeed())
+			Expect(shoot).To(Equal(expected))
+		})
+	})
+})
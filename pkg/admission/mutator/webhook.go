@@ -0,0 +1,63 @@
+package mutator
+
+import (
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/pkg/apis/core"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	aclv1alpha1 "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/v1alpha1"
+)
+
+const (
+	// Name is a name for a mutation webhook.
+	Name = "mutator"
+	// DefaulterName is a name for the mutation webhook injecting the acl
+	// extension into new shoots of projects labeled with LabelInjectDefaults.
+	DefaulterName = "defaulter"
+)
+
+var logger = log.Log.WithName("acl-mutator-webhook")
+
+// New creates a new webhook that mutates Shoot resources.
+func New(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
+	logger.Info("Setting up webhook", "name", Name)
+
+	codecs := serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict)
+	info, _ := runtime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), runtime.ContentTypeJSON)
+
+	return extensionswebhook.New(mgr, extensionswebhook.Args{
+		Name: Name,
+		Path: "/webhooks/mutate",
+		Mutators: map[extensionswebhook.Mutator][]extensionswebhook.Type{
+			NewShootMutator(codecs.UniversalDecoder(), codecs.EncoderForVersion(info.Serializer, aclv1alpha1.SchemeGroupVersion)): {{Obj: &core.Shoot{}}},
+		},
+		Target: extensionswebhook.TargetSeed,
+		ObjectSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{LabelExtension: "true"},
+		},
+	})
+}
+
+// NewDefaulter creates a new webhook that injects the acl extension into new
+// Shoot resources. As these don't have the acl extension yet, it receives all
+// Shoots. The defaults are read without cache, as they are only needed when
+// creating Shoots.
+func NewDefaulter(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
+	logger.Info("Setting up webhook", "name", DefaulterName)
+
+	codecs := serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict)
+	info, _ := runtime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), runtime.ContentTypeJSON)
+
+	return extensionswebhook.New(mgr, extensionswebhook.Args{
+		Name: DefaulterName,
+		Path: "/webhooks/default",
+		Mutators: map[extensionswebhook.Mutator][]extensionswebhook.Type{
+			NewShootDefaulter(codecs.UniversalDecoder(), codecs.EncoderForVersion(info.Serializer, aclv1alpha1.SchemeGroupVersion), mgr.GetAPIReader()): {{Obj: &core.Shoot{}}},
+		},
+		Target: extensionswebhook.TargetSeed,
+	})
+}
@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"context"
+	"slices"
+
+	"github.com/gardener/gardener/pkg/apis/core"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/helper"
+)
+
+// resolveCIDRsFrom replaces the cidrsFrom references of the rules of the given
+// ACLConfig with the CIDRs of the referenced resources, so they are validated
+// like the other CIDRs. References to resources the webhook cannot read, e.g.
+// because they do not exist yet or the webhook is not allowed to read Secrets,
+// are kept and only resolved by the controller. References to resources not
+// listed in spec.resources and invalid content are returned as field errors.
+func (s *shootValidator) resolveCIDRsFrom(ctx context.Context, shoot *core.Shoot, config *acl.ACLConfig) (field.ErrorList, error) {
+	refs := config.AllCIDRsFrom()
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	var (
+		allErrs   = field.ErrorList{}
+		cidrsFrom = make(map[acl.CIDRsFromReference][]string, len(refs))
+	)
+	for _, ref := range refs {
+		index := slices.IndexFunc(shoot.Spec.Resources, func(resource core.NamedResourceReference) bool {
+			return resource.Name == ref.ResourceName
+		})
+		if index < 0 {
+			allErrs = append(allErrs, field.NotFound(field.NewPath("spec", "resources"), ref.ResourceName))
+			continue
+		}
+		resource := shoot.Spec.Resources[index]
+		fldPath := field.NewPath("spec", "resources").Index(index)
+
+		if kind := resource.ResourceRef.Kind; kind != "Secret" && kind != "ConfigMap" {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("resourceRef", "kind"), kind, []string{"ConfigMap", "Secret"}))
+			continue
+		}
+		if s.apiReader == nil {
+			continue
+		}
+
+		data, err := helper.GetReferencedData(ctx, s.apiReader, resource.ResourceRef.Kind, shoot.Namespace, resource.ResourceRef.Name)
+		if err != nil {
+			logger.V(1).Info("Skipping validation of unreadable resource referenced by cidrsFrom", "namespace", shoot.Namespace, "resource", ref.ResourceName, "error", err.Error())
+			continue
+		}
+		cidrs, err := helper.ParseReferencedCIDRs(data, ref.Key)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, ref.ResourceName, err.Error()))
+			continue
+		}
+		cidrsFrom[ref] = cidrs
+	}
+
+	// the CIDRs are only added if all references could be resolved, otherwise
+	// the referenced CIDRs are only validated by the controller
+	if len(allErrs) > 0 || len(cidrsFrom) < len(refs) {
+		return allErrs, nil
+	}
+	return nil, aclhelper.ResolveCIDRsFrom(config, cidrsFrom)
+}
@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"context"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Warner is implemented by validators which return admission warnings for the
+// objects they accept.
+type Warner interface {
+	// Warnings returns the admission warnings for the given object.
+	Warnings(ctx context.Context, new, old client.Object) ([]string, error)
+}
+
+// warningHandler wraps the admission handler of a validating webhook, so the
+// responses to accepted requests contain the warnings of a Warner.
+type warningHandler struct {
+	handler admission.Handler
+	warner  Warner
+	decoder runtime.Decoder
+	newObj  func() client.Object
+}
+
+// Handle implements admission.Handler.
+func (h *warningHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	resp := h.handler.Handle(ctx, req)
+	if !resp.Allowed || req.Operation == admissionv1.Delete {
+		return resp
+	}
+
+	newObj, err := h.decode(req.Object)
+	if err != nil {
+		logger.Error(err, "Could not decode object to compute warnings", "name", req.Name, "namespace", req.Namespace)
+		return resp
+	}
+
+	var oldObj client.Object
+	if len(req.OldObject.Raw) > 0 {
+		if oldObj, err = h.decode(req.OldObject); err != nil {
+			logger.Error(err, "Could not decode old object to compute warnings", "name", req.Name, "namespace", req.Namespace)
+			return resp
+		}
+	}
+
+	warnings, err := h.warner.Warnings(ctx, newObj, oldObj)
+	if err != nil {
+		logger.Error(err, "Could not compute warnings", "name", req.Name, "namespace", req.Namespace)
+		return resp
+	}
+	return resp.WithWarnings(warnings...)
+}
+
+func (h *warningHandler) decode(raw runtime.RawExtension) (client.Object, error) {
+	obj := h.newObj()
+	if _, _, err := h.decoder.Decode(raw.Raw, nil, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
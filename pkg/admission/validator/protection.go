@@ -0,0 +1,148 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/gardener/gardener/pkg/apis/core"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/helper"
+)
+
+const (
+	// AnnotationRemovalProtection is the annotation on a shoot enabling the
+	// removal protection of its acl extension. The protection can also be
+	// enabled for all shoots of a project by labeling the project or its
+	// namespace with LabelRemovalProtection.
+	AnnotationRemovalProtection = "acl.extensions.gardener.cloud/removal-protection"
+	// LabelRemovalProtection is the label on a project or its namespace
+	// enabling the removal protection for all shoots of the project.
+	LabelRemovalProtection = "acl.extensions.gardener.cloud/removal-protection"
+	// AnnotationConfirmRemoval is the annotation on a shoot confirming the
+	// removal of its protected acl extension. Its value must be the name of the
+	// shoot and it must be set in the same request as the removal.
+	AnnotationConfirmRemoval = "acl.extensions.gardener.cloud/confirm-removal"
+)
+
+// validateRemoval rejects removing or disabling the acl extension of a
+// protected shoot as well as changing its config such that it effectively
+// allows all addresses, i.e. switching to shadow mode or changing the rules of
+// an endpoint to allow all addresses, unless the change is confirmed with
+// AnnotationConfirmRemoval.
+func (s *shootValidator) validateRemoval(ctx context.Context, shoot, oldShoot *core.Shoot) (field.ErrorList, error) {
+	oldConfig, _, err := s.decodeEnabledConfig(oldShoot)
+	if err != nil || oldConfig == nil || len(oldConfig.AllRules()) == 0 {
+		return nil, nil //nolint:nilerr // there is nothing to protect
+	}
+	if isRemovalConfirmed(shoot, oldShoot) {
+		return nil, nil
+	}
+
+	protected, err := s.isRemovalProtected(ctx, shoot, oldShoot)
+	if err != nil || !protected {
+		return nil, err
+	}
+
+	config, fldPath, err := s.decodeEnabledConfig(shoot)
+	if err != nil {
+		return nil, nil //nolint:nilerr // the error is reported by validateShoot
+	}
+
+	confirmation := fmt.Sprintf("confirm by annotating the shoot with %s=%s in the same request", AnnotationConfirmRemoval, shoot.Name)
+	switch aclExtension, extensionIndex := s.findExtension(shoot); {
+	case aclExtension == nil:
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "extensions"),
+			"the acl extension is protected against removal, "+confirmation)}, nil
+	case config == nil:
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "extensions").Index(extensionIndex).Child("disabled"),
+			"the acl extension is protected against disabling, "+confirmation)}, nil
+	case config.Mode == acl.ModeShadow && oldConfig.Mode != acl.ModeShadow:
+		return field.ErrorList{field.Forbidden(fldPath.Child("mode"),
+			"the acl extension is protected against switching to shadow mode, "+confirmation)}, nil
+	}
+
+	newRuleSets, oldRuleSets := ruleSets(config), ruleSets(oldConfig)
+	for _, name := range slices.Sorted(maps.Keys(newRuleSets)) {
+		oldRules, ok := oldRuleSets[name]
+		if !ok {
+			// components without own rules use the rules of the ingress endpoint
+			oldRules = oldRuleSets["ingress"]
+		}
+		if allowsAllAddresses(newRuleSets[name]) && !allowsAllAddresses(oldRules) {
+			return field.ErrorList{field.Forbidden(fldPath,
+				fmt.Sprintf("the acl extension is protected against allowing all addresses to the %s endpoint, %s", name, confirmation))}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ruleSets returns the effective rules of all endpoints and ingress components
+// of the given ACLConfig by their name.
+func ruleSets(config *acl.ACLConfig) map[string][]acl.ACLRule {
+	sets := map[string][]acl.ACLRule{
+		"apiServer": config.GetAPIServerRules(),
+		"vpn":       config.GetVPNRules(),
+		"httpProxy": config.GetHTTPProxyRules(),
+		"ingress":   config.GetIngressRules(),
+	}
+	for name, rules := range config.GetIngressComponentRules() {
+		sets["ingress.components."+name] = rules
+	}
+	return sets
+}
+
+// isRemovalProtected checks whether the given shoot (or its old version) is
+// annotated with AnnotationRemovalProtection or its project or namespace is
+// labeled with LabelRemovalProtection. Checking the old shoot prevents removing
+// the annotation in the same request as the extension.
+func (s *shootValidator) isRemovalProtected(ctx context.Context, shoot, oldShoot *core.Shoot) (bool, error) {
+	if shoot.Annotations[AnnotationRemovalProtection] == "true" || oldShoot.Annotations[AnnotationRemovalProtection] == "true" {
+		return true, nil
+	}
+
+	ns, project, err := helper.GetProjectForNamespace(ctx, s.client, shoot.Namespace)
+	if err != nil {
+		return false, err
+	}
+	return helper.HasProjectLabel(ns, project, LabelRemovalProtection), nil
+}
+
+// isRemovalConfirmed checks whether AnnotationConfirmRemoval is set to the name
+// of the shoot in the given request. A confirmation left over from an earlier
+// request does not count.
+func isRemovalConfirmed(shoot, oldShoot *core.Shoot) bool {
+	return shoot.Annotations[AnnotationConfirmRemoval] == shoot.Name &&
+		oldShoot.Annotations[AnnotationConfirmRemoval] != shoot.Name
+}
+
+// allowsAllAddresses checks whether the given rules effectively allow all
+// addresses, i.e. they contain no ALLOW rule or the CIDRs of their ALLOW rules
+// together cover the whole IPv4 or IPv6 address space, e.g. 0.0.0.0/1 and
+// 128.0.0.0/1.
+func allowsAllAddresses(rules []acl.ACLRule) bool {
+	allowed := &aclhelper.CIDRSet{}
+	hasAllowRule := false
+	for _, rule := range rules {
+		if !strings.EqualFold(rule.Action, "ALLOW") {
+			continue
+		}
+		hasAllowRule = true
+		allowed.InsertCIDRs(rule.GetCIDRs()...)
+	}
+	if !hasAllowRule {
+		return true
+	}
+
+	// the set merges adjacent prefixes, so prefixes covering the whole address
+	// space together end up as ::/0 or 0.0.0.0/0
+	return slices.ContainsFunc(allowed.Prefixes(), func(prefix netip.Prefix) bool {
+		return prefix.Bits() == 0
+	})
+}
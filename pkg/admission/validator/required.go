@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/pkg/apis/core"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/helper"
+)
+
+// LabelACLRequired is the label on the namespace of a project requiring all
+// shoots of the project to have an enabled acl extension with rules. It is not
+// read from the Project, as the members of a project can remove it themselves.
+const LabelACLRequired = "acl.gardener.cloud/required"
+
+// NewRequiredValidator returns a new instance of a requiredValidator. The decoder
+// is used to decode the providerConfig of the acl extension, the client to read
+// the project of the shoot.
+func NewRequiredValidator(decoder runtime.Decoder, c client.Reader) extensionswebhook.Validator {
+	return &requiredValidator{shootValidator: &shootValidator{decoder: decoder, client: c}}
+}
+
+// requiredValidator rejects shoots of projects whose namespace is labeled with
+// LabelACLRequired
+// without an enabled acl extension. Unlike the shootValidator, it receives all
+// shoots, not only the ones with the acl extension.
+type requiredValidator struct {
+	*shootValidator
+}
+
+// Validate validates that the given shoot has an enabled acl extension with
+// rules, if its project requires one.
+func (r *requiredValidator) Validate(ctx context.Context, new, old client.Object) error {
+	shoot, ok := new.(*core.Shoot)
+	if !ok {
+		return fmt.Errorf("wrong object type %T", new)
+	}
+
+	if shoot.DeletionTimestamp != nil {
+		return nil
+	}
+	// don't block changes of the metadata of existing shoots, e.g. by gardener
+	// controllers adding finalizers or annotations
+	if oldShoot, ok := old.(*core.Shoot); ok && apiequality.Semantic.DeepEqual(shoot.Spec, oldShoot.Spec) {
+		return nil
+	}
+
+	extensionSpec, _, err := r.decodeEnabledConfig(shoot)
+	if err != nil {
+		return err
+	}
+	if extensionSpec != nil && len(extensionSpec.AllRules()) > 0 {
+		return nil
+	}
+
+	ns, _, err := helper.GetProjectForNamespace(ctx, r.client, shoot.Namespace)
+	if err != nil {
+		return err
+	}
+	if ns == nil || ns.Labels[LabelACLRequired] != "true" {
+		return nil
+	}
+
+	return field.ErrorList{field.Required(field.NewPath("spec", "extensions"),
+		"the project requires an enabled acl extension with at least one rule")}.ToAggregate()
+}
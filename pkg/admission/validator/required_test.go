@@ -0,0 +1,124 @@
+package validator_test
+
+import (
+	"context"
+
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/pkg/apis/core"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/admission/validator"
+	aclinstall "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/install"
+)
+
+var _ = Describe("Required validator", func() {
+	var (
+		namespace *corev1.Namespace
+		project   *gardencorev1beta1.Project
+		shoot     *core.Shoot
+
+		ctx = context.Background()
+	)
+
+	BeforeEach(func() {
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "garden-dev",
+				Labels: map[string]string{
+					"project.gardener.cloud/name": "dev",
+					"acl.gardener.cloud/required": "true",
+				},
+			},
+		}
+		project = &gardencorev1beta1.Project{
+			ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+		}
+
+		shoot = &core.Shoot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "garden-dev",
+			},
+			Spec: core.ShootSpec{
+				Extensions: []core.Extension{
+					{
+						Type:           "acl",
+						ProviderConfig: &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24"],"type":"remote_ip"}}`)},
+					},
+				},
+			},
+		}
+	})
+
+	newValidator := func() extensionswebhook.Validator {
+		scheme := runtime.NewScheme()
+		aclinstall.Install(scheme)
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+		fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, project).Build()
+		return validator.NewRequiredValidator(serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(), fakeClient)
+	}
+
+	matchRequiredError := func() OmegaMatcher {
+		return ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+			"Type":  Equal(field.ErrorTypeRequired),
+			"Field": Equal("spec.extensions"),
+		})))
+	}
+
+	It("should succeed if the shoot has an enabled acl extension", func() {
+		Expect(newValidator().Validate(ctx, shoot, nil)).To(Succeed())
+	})
+
+	It("should return err if the shoot has no acl extension", func() {
+		shoot.Spec.Extensions = nil
+		Expect(newValidator().Validate(ctx, shoot, nil)).To(matchRequiredError())
+	})
+
+	It("should return err if the acl extension is disabled", func() {
+		shoot.Spec.Extensions[0].Disabled = ptr.To(true)
+		Expect(newValidator().Validate(ctx, shoot, nil)).To(matchRequiredError())
+	})
+
+	It("should return err if the acl extension has no rules", func() {
+		shoot.Spec.Extensions[0].ProviderConfig = nil
+		Expect(newValidator().Validate(ctx, shoot, nil)).To(matchRequiredError())
+	})
+
+	It("should succeed if the namespace does not require the acl extension", func() {
+		delete(namespace.Labels, validator.LabelACLRequired)
+		shoot.Spec.Extensions = nil
+		Expect(newValidator().Validate(ctx, shoot, nil)).To(Succeed())
+	})
+
+	It("should ignore the label of the project", func() {
+		delete(namespace.Labels, validator.LabelACLRequired)
+		project.Labels = map[string]string{validator.LabelACLRequired: "true"}
+		shoot.Spec.Extensions = nil
+		Expect(newValidator().Validate(ctx, shoot, nil)).To(Succeed())
+	})
+
+	It("should succeed if only the metadata of a shoot without acl extension changes", func() {
+		shoot.Spec.Extensions = nil
+		newShoot := shoot.DeepCopy()
+		newShoot.Finalizers = []string{"gardener"}
+		Expect(newValidator().Validate(ctx, newShoot, shoot)).To(Succeed())
+	})
+
+	It("should return err if the spec of a shoot without acl extension changes", func() {
+		shoot.Spec.Extensions = nil
+		newShoot := shoot.DeepCopy()
+		newShoot.Spec.Purpose = ptr.To(core.ShootPurposeProduction)
+		Expect(newValidator().Validate(ctx, newShoot, shoot)).To(matchRequiredError())
+	})
+})
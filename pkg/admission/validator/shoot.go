@@ -2,45 +2,99 @@ package validator
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	"github.com/gardener/gardener/pkg/apis/core"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/validation"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/controller"
-	"github.com/stackitcloud/gardener-extension-acl/pkg/extensionspec"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/helper"
 )
 
-// NewShootValidator returns a new instance of a shootValidator.
-func NewShootValidator() extensionswebhook.Validator {
-	return &shootValidator{}
+// NewShootValidator returns a new instance of a shootValidator. The decoder is
+// used to decode the providerConfig of the acl extension, the client to read the
+// project and the seed of the shoot and the apiReader to read the resources
+// referenced with cidrsFrom without cache.
+func NewShootValidator(decoder runtime.Decoder, c, apiReader client.Reader) extensionswebhook.Validator {
+	return &shootValidator{decoder: decoder, client: c, apiReader: apiReader}
 }
 
+const (
+	// LabelPolicyExempt is the label on the namespace of a project exempting the
+	// shoots of the project from the policy. It is not read from the Project, as
+	// the members of a project can label it themselves.
+	LabelPolicyExempt = "acl.extensions.gardener.cloud/policy-exempt"
+	// AnnotationMaxAllowedCIDRs is the annotation on the namespace of a project
+	// overriding MaxAllowedCIDRs for the shoots of the project. It is not read
+	// from the Project, as the members of a project can annotate it themselves.
+	AnnotationMaxAllowedCIDRs = "acl.extensions.gardener.cloud/max-allowed-cidrs"
+)
+
 // DefaultAddOptions are the default options to apply when adding the webhook to the manager.
 var DefaultAddOptions = AddOptions{}
 
 // AddOptions are options to apply when adding the webhook to the manager.
 type AddOptions struct {
+	// MaxAllowedCIDRs is the maximum number of CIDRs per shoot, unless
+	// overridden with AnnotationMaxAllowedCIDRs. Zero means no limit.
 	MaxAllowedCIDRs int
+	// AdditionalAllowedCIDRs are the CIDRs the extension allows for every
+	// shoot in addition to the node and pod CIDRs of the seed.
+	AdditionalAllowedCIDRs []string
+	// Policy is enforced for the rules of all shoots, except for the ones of
+	// projects whose namespace is labeled with LabelPolicyExempt.
+	Policy validation.Policy
 }
 
-type shootValidator struct{}
+type shootValidator struct {
+	decoder runtime.Decoder
+	client  client.Reader
+	// apiReader reads the resources referenced with cidrsFrom, which are not
+	// cached. If it is nil, their content is not validated.
+	apiReader client.Reader
+}
 
-// Validate validates the given shoot object.
-func (s *shootValidator) Validate(ctx context.Context, new, _ client.Object) error {
+// Validate validates the given shoot object. On updates, the old shoot is used
+// to accept configs exceeding MaxAllowedCIDRs as long as they do not grow and
+// to accept CIDR entries which expired since they were added.
+func (s *shootValidator) Validate(ctx context.Context, new, old client.Object) error {
 	shoot, ok := new.(*core.Shoot)
 	if !ok {
 		return fmt.Errorf("wrong object type %T", new)
 	}
-	return s.validateShoot(ctx, shoot)
+
+	var (
+		oldCIDRs  []string
+		oldConfig *acl.ACLConfig
+	)
+	if oldShoot, ok := old.(*core.Shoot); ok {
+		allErrs, err := s.validateRemoval(ctx, shoot, oldShoot)
+		if err != nil {
+			return err
+		}
+		if len(allErrs) > 0 {
+			return allErrs.ToAggregate()
+		}
+		oldCIDRs = s.cidrsOf(ctx, oldShoot)
+		// like in cidrsOf, errors decoding the old config are ignored
+		oldConfig, _, _ = s.decodeEnabledConfig(oldShoot)
+	}
+	return s.validateShoot(ctx, shoot, oldCIDRs, oldConfig)
 }
 
-func (s *shootValidator) validateShoot(_ context.Context, shoot *core.Shoot) error {
+func (s *shootValidator) validateShoot(ctx context.Context, shoot *core.Shoot, oldCIDRs []string, oldConfig *acl.ACLConfig) error {
 	aclExtension, extensionIndex := s.findExtension(shoot)
 	if aclExtension == nil {
 		return nil
@@ -51,24 +105,170 @@ func (s *shootValidator) validateShoot(_ context.Context, shoot *core.Shoot) err
 		return nil
 	}
 
-	extensionSpec, err := s.decodeExtensionSpec(aclExtension.ProviderConfig)
+	extensionSpec, err := aclhelper.DecodeACLConfig(s.decoder, aclExtension.ProviderConfig)
 	if err != nil {
 		return fmt.Errorf("error decoding ACL extension spec: %w", err)
 	}
 
-	if extensionSpec == nil || extensionSpec.Rule == nil {
+	ns, _, err := helper.GetProjectForNamespace(ctx, s.client, shoot.Namespace)
+	if err != nil {
+		return err
+	}
+	maxAllowedCIDRs, err := getMaxAllowedCIDRs(ns)
+	if err != nil {
+		return err
+	}
+
+	// the policy is checked before resolving the cidrsFrom references, which
+	// are forbidden by it
+	policyErrs := validatePolicy(ns, extensionSpec, fldPath)
+
+	allErrs, err := s.resolveCIDRsFrom(ctx, shoot, extensionSpec)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	allErrs = append(allErrs, validation.ValidateACLConfigUpdate(extensionSpec, oldCIDRs, maxAllowedCIDRs, now, fldPath)...)
+	allErrs = append(allErrs, validation.ValidateCIDREntryExpiry(extensionSpec, oldConfig, now, fldPath)...)
+	allErrs = append(allErrs, policyErrs...)
+
+	return allErrs.ToAggregate()
+}
+
+// ValidateDefaultACLConfig validates an ACLConfig injected into a new shoot of
+// the project with the given namespace like the shoot validator: against the
+// maximum number of CIDRs of the namespace and, unless the namespace is exempt,
+// the policy. ns may be nil.
+func ValidateDefaultACLConfig(ns *corev1.Namespace, config *acl.ACLConfig, fldPath *field.Path) (field.ErrorList, error) {
+	maxAllowedCIDRs, err := getMaxAllowedCIDRs(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	allErrs := validation.ValidateACLConfig(config, maxAllowedCIDRs, fldPath)
+	return append(allErrs, validatePolicy(ns, config, fldPath)...), nil
+}
+
+// validatePolicy checks the given ACLConfig against the policy, unless the
+// given namespace is labeled with LabelPolicyExempt.
+func validatePolicy(ns *corev1.Namespace, config *acl.ACLConfig, fldPath *field.Path) field.ErrorList {
+	if DefaultAddOptions.Policy.IsEmpty() || (ns != nil && ns.Labels[LabelPolicyExempt] == "true") {
 		return nil
 	}
+	return validation.ValidateACLConfigPolicy(config, &DefaultAddOptions.Policy, fldPath)
+}
+
+// getMaxAllowedCIDRs returns the maximum number of CIDRs for the shoots of the
+// given namespace: the value of AnnotationMaxAllowedCIDRs on the namespace or,
+// without the annotation, the global MaxAllowedCIDRs.
+func getMaxAllowedCIDRs(ns *corev1.Namespace) (int, error) {
+	if ns == nil {
+		return DefaultAddOptions.MaxAllowedCIDRs, nil
+	}
+	value, ok := ns.Annotations[AnnotationMaxAllowedCIDRs]
+	if !ok {
+		return DefaultAddOptions.MaxAllowedCIDRs, nil
+	}
+
+	maxAllowedCIDRs, err := strconv.Atoi(value)
+	if err != nil || maxAllowedCIDRs < 0 {
+		return 0, fmt.Errorf("invalid value %q of annotation %s on namespace %s, must be a non-negative integer",
+			value, AnnotationMaxAllowedCIDRs, ns.Name)
+	}
+	return maxAllowedCIDRs, nil
+}
+
+// Warnings returns warnings for valid, but questionable rules of the acl
+// extension of the given shoot, see validation.GetWarnings.
+func (s *shootValidator) Warnings(ctx context.Context, new, _ client.Object) ([]string, error) {
+	shoot, ok := new.(*core.Shoot)
+	if !ok {
+		return nil, fmt.Errorf("wrong object type %T", new)
+	}
+
+	aclExtension, extensionIndex := s.findExtension(shoot)
+	if aclExtension == nil || (aclExtension.Disabled != nil && *aclExtension.Disabled) {
+		return nil, nil
+	}
+	fldPath := field.NewPath("spec", "extensions").Index(extensionIndex).Child("providerConfig")
+
+	extensionSpec, err := aclhelper.DecodeACLConfig(s.decoder, aclExtension.ProviderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding ACL extension spec: %w", err)
+	}
+
+	alwaysAllowedCIDRs, err := s.alwaysAllowedCIDRs(ctx, shoot)
+	if err != nil {
+		return nil, err
+	}
+
+	ns, _, err := helper.GetProjectForNamespace(ctx, s.client, shoot.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	maxAllowedCIDRs, err := getMaxAllowedCIDRs(ns)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := controller.ValidateExtensionSpec(extensionSpec, DefaultAddOptions.MaxAllowedCIDRs); err != nil {
-		// field error for too many CIDRs
-		if errors.Is(err, controller.ErrSpecTooManyCIDRs) {
-			return field.TooMany(fldPath.Child("rule", "cidrs"), len(extensionSpec.Rule.Cidrs), DefaultAddOptions.MaxAllowedCIDRs)
+	warnings := validation.GetWarnings(extensionSpec, alwaysAllowedCIDRs, fldPath)
+	if maxAllowedCIDRs > 0 {
+		if numCIDRs := len(extensionSpec.UnexpiredCIDRs(time.Now())); numCIDRs > maxAllowedCIDRs {
+			warnings = append(warnings, fmt.Sprintf("%s: the config contains %d CIDRs, exceeding the limit of %d, updates must not add CIDRs", fldPath, numCIDRs, maxAllowedCIDRs))
 		}
-		return err
 	}
+	return warnings, nil
+}
 
-	return nil
+// cidrsOf returns the CIDRs of the enabled acl extension of the given shoot,
+// including the ones of resolvable cidrsFrom references. Errors decoding the
+// providerConfig are ignored, as the old shoot is only used to grandfather
+// existing configs.
+func (s *shootValidator) cidrsOf(ctx context.Context, shoot *core.Shoot) []string {
+	extensionSpec, _, err := s.decodeEnabledConfig(shoot)
+	if err != nil || extensionSpec == nil {
+		return nil
+	}
+	if _, err := s.resolveCIDRsFrom(ctx, shoot, extensionSpec); err != nil {
+		return nil
+	}
+	return extensionSpec.UnexpiredCIDRs(time.Now())
+}
+
+// decodeEnabledConfig returns the decoded providerConfig of the acl extension
+// of the given shoot and its field path. If the extension is missing or
+// disabled, the config is nil.
+func (s *shootValidator) decodeEnabledConfig(shoot *core.Shoot) (*acl.ACLConfig, *field.Path, error) {
+	aclExtension, extensionIndex := s.findExtension(shoot)
+	if aclExtension == nil || (aclExtension.Disabled != nil && *aclExtension.Disabled) {
+		return nil, nil, nil
+	}
+	fldPath := field.NewPath("spec", "extensions").Index(extensionIndex).Child("providerConfig")
+
+	extensionSpec, err := aclhelper.DecodeACLConfig(s.decoder, aclExtension.ProviderConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decoding ACL extension spec: %w", err)
+	}
+	return extensionSpec, fldPath, nil
+}
+
+// alwaysAllowedCIDRs returns the CIDRs the extension allows for every shoot on
+// the seed of the given shoot. If the shoot is not scheduled yet, only the
+// AdditionalAllowedCIDRs are returned.
+func (s *shootValidator) alwaysAllowedCIDRs(ctx context.Context, shoot *core.Shoot) ([]string, error) {
+	cidrs := slices.Clone(DefaultAddOptions.AdditionalAllowedCIDRs)
+	if shoot.Spec.SeedName == nil {
+		return cidrs, nil
+	}
+
+	seed := &gardencorev1beta1.Seed{}
+	if err := s.client.Get(ctx, client.ObjectKey{Name: *shoot.Spec.SeedName}, seed); err != nil {
+		if apierrors.IsNotFound(err) {
+			return cidrs, nil
+		}
+		return nil, fmt.Errorf("error getting seed %s: %w", *shoot.Spec.SeedName, err)
+	}
+	return append(cidrs, helper.GetSeedSpecificAllowedCIDRs(seed)...), nil
 }
 
 func (s *shootValidator) findExtension(shoot *core.Shoot) (*core.Extension, int) {
@@ -79,14 +279,3 @@ func (s *shootValidator) findExtension(shoot *core.Shoot) (*core.Extension, int)
 	}
 	return nil, 0
 }
-
-func (s *shootValidator) decodeExtensionSpec(aclExt *runtime.RawExtension) (*extensionspec.ExtensionSpec, error) {
-	extSpec := &extensionspec.ExtensionSpec{}
-
-	if aclExt != nil && aclExt.Raw != nil {
-		if err := json.Unmarshal(aclExt.Raw, &extSpec); err != nil {
-			return nil, err
-		}
-	}
-	return extSpec, nil
-}
@@ -2,19 +2,28 @@ package validator_test
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	"github.com/gardener/gardener/pkg/apis/core"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/stackitcloud/gardener-extension-acl/pkg/admission/validator"
-	"github.com/stackitcloud/gardener-extension-acl/pkg/controller"
+	aclinstall "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/install"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/validation"
 )
 
 var _ = Describe("Shoot validator", func() {
@@ -32,7 +41,12 @@ var _ = Describe("Shoot validator", func() {
 		)
 
 		BeforeEach(func() {
-			shootValidator = validator.NewShootValidator()
+			scheme := runtime.NewScheme()
+			aclinstall.Install(scheme)
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+			fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+			shootValidator = validator.NewShootValidator(serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(), fakeClient, fakeClient)
 			validator.DefaultAddOptions.MaxAllowedCIDRs = 5
 
 			shoot = &core.Shoot{
@@ -59,10 +73,39 @@ var _ = Describe("Shoot validator", func() {
 			It("should return err if too many cidrs are specified in acl extension", func() {
 				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(tooManyCIDRs)}
 				err := shootValidator.Validate(ctx, shoot, nil)
-				Expect(err).To(PointTo(MatchFields(IgnoreExtras, Fields{
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":  Equal(field.ErrorTypeTooMany),
 					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
-				})))
+				}))))
+			})
+
+			It("should return err if too many cidrs are specified across the rules of the acl extension", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rules":[{"action":"DENY","cidrs":["10.1.2.3/32"],"type":"remote_ip"},{"action":"ALLOW","cidrs":["10.0.0.0/8","165.1.187.201/32","165.1.187.202/32","165.1.187.203/32","165.1.187.207/32"],"type":"remote_ip"}]}`)}
+				err := shootValidator.Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeTooMany),
+					"Field": Equal("spec.extensions[0].providerConfig.rules"),
+				}))))
+			})
+
+			It("should succeed if apiVersion and kind are specified in acl extension", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"ACLConfig","rule":{"action":"ALLOW","cidrs":["1.2.3.4/24"],"type":"remote_ip"}}`)}
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err if unknown fields are specified in acl extension", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidr":["1.2.3.4/24"],"type":"remote_ip"}}`)}
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(MatchError(ContainSubstring(`unknown field "rule.cidr"`)))
+			})
+
+			It("should return err if an unknown kind is specified in acl extension", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"Foo","rule":{"action":"ALLOW","cidrs":["1.2.3.4/24"],"type":"remote_ip"}}`)}
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(HaveOccurred())
+			})
+
+			It("should succeed if an ordered list of rules is specified in acl extension", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rules":[{"action":"DENY","cidrs":["10.1.2.3/32"],"type":"remote_ip"},{"action":"ALLOW","cidrs":["10.0.0.0/8"],"type":"remote_ip"}]}`)}
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
 			})
 
 			It("should succeed if extension is disabled despite having too many CIDRs configured", func() {
@@ -75,88 +118,681 @@ var _ = Describe("Shoot validator", func() {
 				shoot.Spec.Extensions[0].Disabled = ptr.To(false)
 				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(tooManyCIDRs)}
 				err := shootValidator.Validate(ctx, shoot, nil)
-				Expect(err).To(PointTo(MatchFields(IgnoreExtras, Fields{
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":  Equal(field.ErrorTypeTooMany),
 					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
-				})))
+				}))))
 			})
 
 			It("should return err if number of specified cidrs in acl extension is zero", func() {
 				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":[],"type":"remote_ip"}}`)}
 				err := shootValidator.Validate(ctx, shoot, nil)
-				Expect(err).To(Equal(controller.ErrSpecCIDR))
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
+				}))))
+			})
+
+			It("should return err if no rules are specified in acl extension", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{}`)}
+				err := shootValidator.Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("spec.extensions[0].providerConfig.rule"),
+				}))))
+			})
+
+			It("should return err if the acl extension has no providerConfig", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = nil
+				err := shootValidator.Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("spec.extensions[0].providerConfig.rule"),
+				}))))
 			})
 
 			It("should return err if invalid action is specified in acl extension", func() {
 				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"banana","cidrs":["1.2.3.4/24","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32"],"type":"remote_ip"}}`)}
 				err := shootValidator.Validate(ctx, shoot, nil)
-				Expect(err).To(Equal(controller.ErrSpecAction))
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.action"),
+				}))))
 			})
 
 			It("should return err if invalid type is specified in acl extension", func() {
 				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32"],"type":"potato"}}`)}
 				err := shootValidator.Validate(ctx, shoot, nil)
-				Expect(err).To(Equal(controller.ErrSpecType))
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.type"),
+				}))))
 			})
 
 			It("should return err if invalid cidr is specified in acl extension", func() {
 				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["tikka masala","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32"],"type":"remote_ip"}}`)}
 				err := shootValidator.Validate(ctx, shoot, nil)
-				Expect(err).To(PointTo(MatchFields(IgnoreExtras, Fields{
-					"Type": Equal("CIDR address"),
-					"Text": Equal("tikka masala"),
-				})))
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs[0]"),
+				}))))
+			})
+
+			It("should return all errors if multiple fields are invalid in acl extension", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rules":[{"action":"banana","cidrs":["1.2.3.4/24"],"type":"remote_ip"},{"action":"ALLOW","cidrs":["10.250.0.0/16","208.127.57.6/32","tikka masala"],"type":"potato"}]}`)}
+				err := shootValidator.Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("spec.extensions[0].providerConfig.rules[0].action"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("spec.extensions[0].providerConfig.rules[1].type"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("spec.extensions[0].providerConfig.rules[1].cidrs[2]"),
+					})),
+				))
+			})
+		})
+
+		Context("Policy", func() {
+			var (
+				namespace *corev1.Namespace
+				project   *gardencorev1beta1.Project
+			)
+
+			BeforeEach(func() {
+				namespace = &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "garden-dev",
+						Labels: map[string]string{"project.gardener.cloud/name": "dev"},
+					},
+				}
+				project = &gardencorev1beta1.Project{
+					ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+				}
+
+				validator.DefaultAddOptions.Policy = validation.Policy{
+					MinPrefixLengthIPv4: 16,
+					ForbidAllAddresses:  true,
+				}
+				DeferCleanup(func() { validator.DefaultAddOptions.Policy = validation.Policy{} })
+
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["0.0.0.0/0","10.0.0.0/8"],"type":"remote_ip"}}`)}
+			})
+
+			newValidator := func() extensionswebhook.Validator {
+				scheme := runtime.NewScheme()
+				aclinstall.Install(scheme)
+				Expect(corev1.AddToScheme(scheme)).To(Succeed())
+				Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+				fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, project).Build()
+				return validator.NewShootValidator(serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(), fakeClient, fakeClient)
+			}
+
+			It("should return all policy violations", func() {
+				err := newValidator().Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeForbidden),
+						"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs[0]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs[1]"),
+					})),
+				))
+			})
+
+			It("should ignore the label of the project", func() {
+				project.Labels = map[string]string{validator.LabelPolicyExempt: "true"}
+				err := newValidator().Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeForbidden),
+						"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs[0]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs[1]"),
+					})),
+				))
+			})
+
+			It("should succeed if the namespace is exempt from the policy", func() {
+				namespace.Labels[validator.LabelPolicyExempt] = "true"
+				Expect(newValidator().Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err if an ALLOW rule uses hosts", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","hosts":["example.com"],"type":"remote_ip"}}`)}
+				err := newValidator().Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.hosts"),
+				}))))
+			})
+
+			It("should succeed if an ALLOW rule of an exempt project uses hosts", func() {
+				namespace.Labels[validator.LabelPolicyExempt] = "true"
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","hosts":["example.com"],"type":"remote_ip"}}`)}
+				Expect(newValidator().Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should still validate the rules of exempt projects", func() {
+				namespace.Labels[validator.LabelPolicyExempt] = "true"
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["0.0.0.0/0"],"type":"foo"}}`)}
+				err := newValidator().Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.type"),
+				}))))
+			})
+		})
+
+		Context("Namespace specific maximum number of CIDRs", func() {
+			var (
+				namespace *corev1.Namespace
+				project   *gardencorev1beta1.Project
+			)
+
+			BeforeEach(func() {
+				namespace = &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "garden-dev",
+						Labels: map[string]string{"project.gardener.cloud/name": "dev"},
+					},
+				}
+				project = &gardencorev1beta1.Project{
+					ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+				}
+			})
+
+			newValidator := func() extensionswebhook.Validator {
+				scheme := runtime.NewScheme()
+				aclinstall.Install(scheme)
+				Expect(corev1.AddToScheme(scheme)).To(Succeed())
+				Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+				fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, project).Build()
+				return validator.NewShootValidator(serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(), fakeClient, fakeClient)
+			}
+
+			It("should use the maximum of the namespace", func() {
+				namespace.Annotations = map[string]string{validator.AnnotationMaxAllowedCIDRs: "10"}
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(tooManyCIDRs)}
+				Expect(newValidator().Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should ignore the maximum of the project", func() {
+				project.Annotations = map[string]string{validator.AnnotationMaxAllowedCIDRs: "10"}
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(tooManyCIDRs)}
+				err := newValidator().Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeTooMany),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
+				}))))
+			})
+
+			It("should show the maximum of the namespace in the error", func() {
+				namespace.Annotations = map[string]string{validator.AnnotationMaxAllowedCIDRs: "1"}
+				err := newValidator().Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":     Equal(field.ErrorTypeTooMany),
+					"Field":    Equal("spec.extensions[0].providerConfig.rule.cidrs"),
+					"BadValue": Equal(2),
+				}))))
+				Expect(err).To(MatchError(ContainSubstring("must have at most 1 item")))
+			})
+
+			It("should return err if the annotation is invalid", func() {
+				namespace.Annotations = map[string]string{validator.AnnotationMaxAllowedCIDRs: "many"}
+				Expect(newValidator().Validate(ctx, shoot, nil)).To(MatchError(ContainSubstring(`invalid value "many" of annotation`)))
+			})
+		})
+
+		Context("CIDRs from referenced resources", func() {
+			var configMap *corev1.ConfigMap
+
+			BeforeEach(func() {
+				configMap = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "allow-list", Namespace: namespace},
+					Data:       map[string]string{"cidrs": "1.2.3.0/24,10.250.0.0/16"},
+				}
+				shoot.Spec.Resources = []core.NamedResourceReference{{
+					Name:        "acl-cidrs",
+					ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "ConfigMap", Name: "allow-list", APIVersion: "v1"},
+				}}
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrsFrom":{"resourceName":"acl-cidrs"},"type":"remote_ip"}}`)}
+			})
+
+			newValidator := func(objects ...client.Object) extensionswebhook.Validator {
+				scheme := runtime.NewScheme()
+				aclinstall.Install(scheme)
+				Expect(corev1.AddToScheme(scheme)).To(Succeed())
+				Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+				fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+				return validator.NewShootValidator(serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(), fakeClient, fakeClient)
+			}
+
+			It("should succeed if the referenced resource contains valid CIDRs", func() {
+				Expect(newValidator(configMap).Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should succeed if the referenced resource cannot be read", func() {
+				Expect(newValidator().Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err if the referenced resource contains invalid CIDRs", func() {
+				configMap.Data["cidrs"] = "1.2.3.0/24,tikka masala"
+				err := newValidator(configMap).Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("spec.resources[0]"),
+				}))))
+			})
+
+			It("should return err if the referenced resource does not contain the key", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrsFrom":{"resourceName":"acl-cidrs","key":"ipv6"},"type":"remote_ip"}}`)}
+				err := newValidator(configMap).Validate(ctx, shoot, nil)
+				Expect(err).To(MatchError(ContainSubstring("key ipv6 not found")))
+			})
+
+			It("should count the CIDRs of the referenced resource", func() {
+				configMap.Data["cidrs"] = "1.2.3.0/24 10.250.0.0/16 208.127.57.6/32 165.1.187.201/32 165.1.187.202/32 165.1.187.203/32"
+				err := newValidator(configMap).Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeTooMany),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
+				}))))
+			})
+
+			It("should return err if the resource is not listed in spec.resources", func() {
+				shoot.Spec.Resources = nil
+				err := newValidator(configMap).Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotFound),
+					"Field": Equal("spec.resources"),
+				}))))
+			})
+
+			It("should return err if the resource is neither a Secret nor a ConfigMap", func() {
+				shoot.Spec.Resources[0].ResourceRef.Kind = "Deployment"
+				err := newValidator(configMap).Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("spec.resources[0].resourceRef.kind"),
+				}))))
+			})
+		})
+
+		Context("Removal protection", func() {
+			var (
+				namespace *corev1.Namespace
+				project   *gardencorev1beta1.Project
+				newShoot  *core.Shoot
+			)
+
+			BeforeEach(func() {
+				namespace = &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "garden-dev",
+						Labels: map[string]string{"project.gardener.cloud/name": "dev"},
+					},
+				}
+				project = &gardencorev1beta1.Project{
+					ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+				}
+
+				shoot.Annotations = map[string]string{validator.AnnotationRemovalProtection: "true"}
+				newShoot = shoot.DeepCopy()
+			})
+
+			newValidator := func() extensionswebhook.Validator {
+				scheme := runtime.NewScheme()
+				aclinstall.Install(scheme)
+				Expect(corev1.AddToScheme(scheme)).To(Succeed())
+				Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+				fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, project).Build()
+				return validator.NewShootValidator(serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(), fakeClient, fakeClient)
+			}
+
+			It("should return err if the extension is removed", func() {
+				newShoot.Spec.Extensions = nil
+				err := newValidator().Validate(ctx, newShoot, shoot)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("spec.extensions"),
+				}))))
+			})
+
+			It("should return err if the extension is disabled", func() {
+				newShoot.Spec.Extensions[0].Disabled = ptr.To(true)
+				err := newValidator().Validate(ctx, newShoot, shoot)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("spec.extensions[0].disabled"),
+				}))))
+			})
+
+			It("should return err if all addresses are allowed", func() {
+				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["0.0.0.0/0"],"type":"remote_ip"}}`)}
+				err := newValidator().Validate(ctx, newShoot, shoot)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("spec.extensions[0].providerConfig"),
+				}))))
+			})
+
+			It("should return err if the allowed CIDRs together cover all addresses", func() {
+				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["0.0.0.0/1","128.0.0.0/1"],"type":"remote_ip"}}`)}
+				err := newValidator().Validate(ctx, newShoot, shoot)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("spec.extensions[0].providerConfig"),
+				}))))
+			})
+
+			It("should return err if all ALLOW rules are removed", func() {
+				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"DENY","cidrs":["1.2.3.4/24"],"type":"remote_ip"}}`)}
+				err := newValidator().Validate(ctx, newShoot, shoot)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("spec.extensions[0].providerConfig"),
+				}))))
+			})
+
+			It("should return err if all addresses are allowed to a single endpoint", func() {
+				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24","10.250.0.0/16"],"type":"remote_ip"},"vpn":{"rule":{"action":"ALLOW","cidrs":["::/0"],"type":"remote_ip"}}}`)}
+				err := newValidator().Validate(ctx, newShoot, shoot)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("spec.extensions[0].providerConfig"),
+					"Detail": ContainSubstring("vpn endpoint"),
+				}))))
+			})
+
+			It("should return err if the extension is switched to shadow mode", func() {
+				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"mode":"Shadow","rule":{"action":"ALLOW","cidrs":["1.2.3.4/24","10.250.0.0/16"],"type":"remote_ip"}}`)}
+				err := newValidator().Validate(ctx, newShoot, shoot)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("spec.extensions[0].providerConfig.mode"),
+				}))))
+			})
+
+			It("should succeed if the extension already allowed all addresses", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"DENY","cidrs":["1.2.3.4/24"],"type":"remote_ip"}}`)}
+				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"DENY","cidrs":["10.250.0.0/16"],"type":"remote_ip"}}`)}
+				Expect(newValidator().Validate(ctx, newShoot, shoot)).To(Succeed())
+			})
+
+			It("should return err if the protection is removed in the same request", func() {
+				newShoot.Annotations = nil
+				newShoot.Spec.Extensions = nil
+				Expect(newValidator().Validate(ctx, newShoot, shoot)).To(HaveOccurred())
+			})
+
+			It("should return err if the project is protected", func() {
+				shoot.Annotations = nil
+				project.Labels = map[string]string{validator.LabelRemovalProtection: "true"}
+				newShoot.Annotations = nil
+				newShoot.Spec.Extensions = nil
+				Expect(newValidator().Validate(ctx, newShoot, shoot)).To(HaveOccurred())
+			})
+
+			It("should succeed if the removal is confirmed", func() {
+				newShoot.Annotations[validator.AnnotationConfirmRemoval] = "foo"
+				newShoot.Spec.Extensions = nil
+				Expect(newValidator().Validate(ctx, newShoot, shoot)).To(Succeed())
+			})
+
+			It("should return err if the confirmation is left over from an earlier request", func() {
+				shoot.Annotations[validator.AnnotationConfirmRemoval] = "foo"
+				newShoot.Annotations[validator.AnnotationConfirmRemoval] = "foo"
+				newShoot.Spec.Extensions = nil
+				Expect(newValidator().Validate(ctx, newShoot, shoot)).To(HaveOccurred())
+			})
+
+			It("should succeed if the shoot is not protected", func() {
+				shoot.Annotations = nil
+				newShoot.Annotations = nil
+				newShoot.Spec.Extensions = nil
+				Expect(newValidator().Validate(ctx, newShoot, shoot)).To(Succeed())
+			})
+
+			It("should succeed if the rules are changed", func() {
+				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24"],"type":"remote_ip"}}`)}
+				Expect(newValidator().Validate(ctx, newShoot, shoot)).To(Succeed())
 			})
 		})
 
 		Context("Shoot update", func() {
 			It("should return err if too many cidrs are specified in acl extension", func() {
-				newShoot := shoot
+				newShoot := shoot.DeepCopy()
 				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32","165.1.187.203/32","165.1.187.207/32","165.1.187.208/32"],"type":"remote_ip"}}`)}
 				err := shootValidator.Validate(ctx, newShoot, shoot)
-				Expect(err).To(PointTo(MatchFields(IgnoreExtras, Fields{
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":  Equal(field.ErrorTypeTooMany),
 					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
-				})))
+				}))))
 			})
 
 			It("should succeed if number of specified cidrs in acl extension is below maximum", func() {
-				newShoot := shoot
+				newShoot := shoot.DeepCopy()
 				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32"],"type":"remote_ip"}}`)}
 				Expect(shootValidator.Validate(ctx, newShoot, shoot)).To(Succeed())
 			})
 
 			It("should return err if number of specified cidrs in acl extension is zero", func() {
-				newShoot := shoot
+				newShoot := shoot.DeepCopy()
 				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":[],"type":"remote_ip"}}`)}
 				err := shootValidator.Validate(ctx, newShoot, shoot)
-				Expect(err).To(Equal(controller.ErrSpecCIDR))
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
+				}))))
 			})
 
 			It("should return err if invalid action is specified in acl extension", func() {
-				newShoot := shoot
+				newShoot := shoot.DeepCopy()
 				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"banana","cidrs":["1.2.3.4/24","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32"],"type":"remote_ip"}}`)}
 				err := shootValidator.Validate(ctx, newShoot, shoot)
-				Expect(err).To(Equal(controller.ErrSpecAction))
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.action"),
+				}))))
 			})
 
 			It("should return err if invalid type is specified in acl extension", func() {
-				newShoot := shoot
+				newShoot := shoot.DeepCopy()
 				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32"],"type":"potato"}}`)}
 				err := shootValidator.Validate(ctx, newShoot, shoot)
-				Expect(err).To(Equal(controller.ErrSpecType))
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.type"),
+				}))))
 			})
 
 			It("should return err if invalid cidr is specified in acl extension", func() {
-				newShoot := shoot
+				newShoot := shoot.DeepCopy()
 				newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["tikka masala","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32"],"type":"remote_ip"}}`)}
 				err := shootValidator.Validate(ctx, newShoot, shoot)
-				Expect(err).To(PointTo(MatchFields(IgnoreExtras, Fields{
-					"Type": Equal("CIDR address"),
-					"Text": Equal("tikka masala"),
-				})))
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs[0]"),
+				}))))
 			})
 
+			Context("with more CIDRs than allowed", func() {
+				BeforeEach(func() {
+					shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(tooManyCIDRs)}
+				})
+
+				It("should succeed if the cidrs are unchanged", func() {
+					newShoot := shoot.DeepCopy()
+					newShoot.Labels = map[string]string{"foo": "bar"}
+					Expect(shootValidator.Validate(ctx, newShoot, shoot)).To(Succeed())
+				})
+
+				It("should succeed if cidrs are removed", func() {
+					newShoot := shoot.DeepCopy()
+					newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32","165.1.187.203/32"],"type":"remote_ip"}}`)}
+					Expect(shootValidator.Validate(ctx, newShoot, shoot)).To(Succeed())
+				})
+
+				It("should return err if a cidr is replaced", func() {
+					newShoot := shoot.DeepCopy()
+					newShoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.4/24","10.250.0.0/16","208.127.57.6/32","165.1.187.201/32","165.1.187.202/32","165.1.187.203/32","165.1.187.207/32","165.1.187.209/32"],"type":"remote_ip"}}`)}
+					err := shootValidator.Validate(ctx, newShoot, shoot)
+					Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeTooMany),
+						"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
+					}))))
+				})
+
+				It("should return err if the old extension is disabled", func() {
+					shoot.Spec.Extensions[0].Disabled = ptr.To(true)
+					newShoot := shoot.DeepCopy()
+					newShoot.Spec.Extensions[0].Disabled = nil
+					err := shootValidator.Validate(ctx, newShoot, shoot)
+					Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeTooMany),
+						"Field": Equal("spec.extensions[0].providerConfig.rule.cidrs"),
+					}))))
+				})
+			})
+		})
+
+		Context("CIDR entries", func() {
+			entryConfig := func(expiresAt time.Time) *runtime.RawExtension {
+				return &runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+					`{"rule":{"action":"ALLOW","cidrs":["10.250.0.0/16"],"cidrEntries":[{"cidr":"1.2.3.4/32","owner":"team-a","expiresAt":%q}],"type":"remote_ip"}}`,
+					expiresAt.UTC().Format(time.RFC3339),
+				))}
+			}
+
+			It("should succeed if the entry expires in the future", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = entryConfig(time.Now().Add(time.Hour))
+				Expect(shootValidator.Validate(ctx, shoot, nil)).To(Succeed())
+			})
+
+			It("should return err if the entry has already expired", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = entryConfig(time.Now().Add(-time.Hour))
+				err := shootValidator.Validate(ctx, shoot, nil)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrEntries[0].expiresAt"),
+				}))))
+			})
+
+			It("should succeed if an entry expired since it was added", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = entryConfig(time.Now().Add(-time.Hour))
+				newShoot := shoot.DeepCopy()
+				newShoot.Labels = map[string]string{"foo": "bar"}
+				Expect(shootValidator.Validate(ctx, newShoot, shoot)).To(Succeed())
+			})
+
+			It("should return err if an expired entry is extended into the past", func() {
+				shoot.Spec.Extensions[0].ProviderConfig = entryConfig(time.Now().Add(-2 * time.Hour))
+				newShoot := shoot.DeepCopy()
+				newShoot.Spec.Extensions[0].ProviderConfig = entryConfig(time.Now().Add(-time.Hour))
+				err := shootValidator.Validate(ctx, newShoot, shoot)
+				Expect(err).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("spec.extensions[0].providerConfig.rule.cidrEntries[0].expiresAt"),
+				}))))
+			})
+		})
+	})
+
+	Describe("#Warnings", func() {
+		var (
+			shootValidator validator.Warner
+
+			shoot *core.Shoot
+
+			ctx = context.Background()
+		)
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			aclinstall.Install(scheme)
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+			seed := &gardencorev1beta1.Seed{
+				ObjectMeta: metav1.ObjectMeta{Name: "seed"},
+				Spec: gardencorev1beta1.SeedSpec{
+					Networks: gardencorev1beta1.SeedNetworks{
+						Nodes: ptr.To("10.250.0.0/16"),
+						Pods:  "100.96.0.0/11",
+					},
+				},
+			}
+			fakeClient := fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(seed).Build()
+			shootValidator = validator.NewShootValidator(serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(), fakeClient, fakeClient).(validator.Warner)
+			validator.DefaultAddOptions.AdditionalAllowedCIDRs = []string{"192.0.2.0/24"}
+			DeferCleanup(func() { validator.DefaultAddOptions.AdditionalAllowedCIDRs = nil })
+
+			shoot = &core.Shoot{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "garden-dev",
+				},
+				Spec: core.ShootSpec{
+					SeedName: ptr.To("seed"),
+					Extensions: []core.Extension{
+						{
+							Type:           "acl",
+							ProviderConfig: &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24","203.0.113.0/24"],"type":"remote_ip"}}`)},
+						},
+					},
+				},
+			}
+		})
+
+		It("should not return warnings for reasonable rules", func() {
+			Expect(shootValidator.Warnings(ctx, shoot, nil)).To(BeEmpty())
+		})
+
+		It("should warn about CIDRs covered by the CIDRs of the seed", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24","10.250.1.0/24"],"type":"remote_ip"}}`)}
+			Expect(shootValidator.Warnings(ctx, shoot, nil)).To(ConsistOf(
+				`spec.extensions[0].providerConfig.rule.cidrs[1]: "10.250.1.0/24" is always allowed, as it is covered by "10.250.0.0/16"`,
+			))
+		})
+
+		It("should warn about CIDRs covered by the additional allowed CIDRs", func() {
+			shoot.Spec.SeedName = nil
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rules":[{"action":"DENY","cidrs":["192.0.2.1/32"],"type":"remote_ip"}]}`)}
+			Expect(shootValidator.Warnings(ctx, shoot, nil)).To(ConsistOf(
+				`spec.extensions[0].providerConfig.rules[0].cidrs[0]: "192.0.2.1/32" cannot be denied, as it is covered by the always allowed "192.0.2.0/24"`,
+			))
+		})
+
+		It("should warn about overlapping, private and broad CIDRs", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["2.0.0.0/7","2.2.3.0/24","172.16.0.0/16"],"type":"remote_ip"}}`)}
+			Expect(shootValidator.Warnings(ctx, shoot, nil)).To(ConsistOf(
+				`spec.extensions[0].providerConfig.rule.cidrs[0]: "2.0.0.0/7" is very broad, it covers 2^25 addresses`,
+				`spec.extensions[0].providerConfig.rule.cidrs[1]: "2.2.3.0/24" overlaps with "2.0.0.0/7" (spec.extensions[0].providerConfig.rule.cidrs[0])`,
+				`spec.extensions[0].providerConfig.rule.cidrs[2]: "172.16.0.0/16" is part of the private range "172.16.0.0/12", connections through the ingress gateway never originate from it`,
+			))
+		})
+
+		It("should warn about more CIDRs than allowed", func() {
+			validator.DefaultAddOptions.MaxAllowedCIDRs = 1
+			DeferCleanup(func() { validator.DefaultAddOptions.MaxAllowedCIDRs = 0 })
+			Expect(shootValidator.Warnings(ctx, shoot, nil)).To(ConsistOf(
+				`spec.extensions[0].providerConfig: the config contains 2 CIDRs, exceeding the limit of 1, updates must not add CIDRs`,
+			))
+		})
+
+		It("should not return warnings if the extension is disabled", func() {
+			shoot.Spec.Extensions[0].ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.0.0.0/7"],"type":"remote_ip"}}`)}
+			shoot.Spec.Extensions[0].Disabled = ptr.To(true)
+			Expect(shootValidator.Warnings(ctx, shoot, nil)).To(BeEmpty())
 		})
 	})
 })
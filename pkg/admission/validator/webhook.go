@@ -4,6 +4,8 @@ import (
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	"github.com/gardener/gardener/pkg/apis/core"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -11,22 +13,62 @@ import (
 const (
 	// Name is a name for a validation webhook.
 	Name = "validator"
+	// RequiredName is a name for the validation webhook enforcing the acl
+	// extension for projects whose namespace is labeled with LabelACLRequired.
+	RequiredName = "required-validator"
 )
 
 var logger = log.Log.WithName("acl-validator-webhook")
 
-// New creates a new webhook that validates Shoot resources.
+// New creates a new webhook that validates Shoot resources. Accepted Shoots are
+// answered with warnings for questionable rules.
 func New(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
 	logger.Info("Setting up webhook", "name", Name)
-	return extensionswebhook.New(mgr, extensionswebhook.Args{
+
+	shootValidator := &shootValidator{
+		decoder:   serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict).UniversalDecoder(),
+		client:    mgr.GetClient(),
+		apiReader: mgr.GetAPIReader(),
+	}
+
+	webhook, err := extensionswebhook.New(mgr, extensionswebhook.Args{
 		Name: Name,
 		Path: "/webhooks/validate",
 		Validators: map[extensionswebhook.Validator][]extensionswebhook.Type{
-			NewShootValidator(): {{Obj: &core.Shoot{}}},
+			shootValidator: {{Obj: &core.Shoot{}}},
 		},
 		Target: extensionswebhook.TargetSeed,
 		ObjectSelector: &metav1.LabelSelector{
 			MatchLabels: map[string]string{"extensions.extensions.gardener.cloud/acl": "true"},
 		},
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the handler of the extensions library can only accept or reject requests,
+	// so wrap it to add the warnings
+	webhook.Webhook.Handler = &warningHandler{
+		handler: webhook.Webhook.Handler,
+		warner:  shootValidator,
+		decoder: serializer.NewCodecFactory(mgr.GetScheme()).UniversalDecoder(),
+		newObj:  func() client.Object { return &core.Shoot{} },
+	}
+	return webhook, nil
+}
+
+// NewRequired creates a new webhook that validates all Shoot resources, not only
+// the ones with the acl extension, and rejects Shoots without an enabled acl
+// extension in projects whose namespace is labeled with LabelACLRequired.
+func NewRequired(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
+	logger.Info("Setting up webhook", "name", RequiredName)
+
+	return extensionswebhook.New(mgr, extensionswebhook.Args{
+		Name: RequiredName,
+		Path: "/webhooks/validate-required",
+		Validators: map[extensionswebhook.Validator][]extensionswebhook.Type{
+			NewRequiredValidator(serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict).UniversalDecoder(), mgr.GetClient()): {{Obj: &core.Shoot{}}},
+		},
+		Target: extensionswebhook.TargetSeed,
+	})
 }
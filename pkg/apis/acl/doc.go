@@ -0,0 +1,5 @@
+// +k8s:deepcopy-gen=package
+// +groupName=acl.extensions.gardener.cloud
+
+// Package acl is the internal version of the ACL extension configuration API.
+package acl
@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"slices"
+	"time"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+// ResolveCIDREntries adds the CIDRs of the CIDR entries of the rules of the
+// given ACLConfig, which have not expired at now, to the CIDRs of the rules and
+// removes the CIDR entries, see ResolveCIDRSets. Rules left without any CIDRs
+// because all of their entries expired are removed like inactive rules, see
+// ApplySchedules. It returns the time the next of the remaining entries
+// expires, or the zero time if none of them expires.
+func ResolveCIDREntries(config *acl.ACLConfig, now time.Time) time.Time {
+	filterRules(config, func(rule *acl.ACLRule) bool {
+		return len(rule.Cidrs) > 0 || len(rule.CIDREntries) == 0 ||
+			slices.ContainsFunc(rule.CIDREntries, func(entry acl.CIDREntry) bool { return !entry.IsExpired(now) })
+	})
+
+	var next time.Time
+	forEachRule(config, func(rule *acl.ACLRule) {
+		if len(rule.CIDREntries) == 0 {
+			return
+		}
+		for _, entry := range rule.CIDREntries {
+			if entry.IsExpired(now) {
+				continue
+			}
+			rule.Cidrs = append(rule.Cidrs, entry.CIDR)
+			if entry.ExpiresAt != nil && (next.IsZero() || entry.ExpiresAt.Time.Before(next)) {
+				next = entry.ExpiresAt.Time
+			}
+		}
+		rule.Cidrs = NormalizeCIDRs(rule.Cidrs)
+		rule.CIDREntries = nil
+	})
+	return next
+}
+
+// ExpiringCIDREntries returns the CIDR entries of the given ACLConfig which have
+// not expired at now, but expire within the given horizon, ordered by their
+// expiry.
+func ExpiringCIDREntries(config *acl.ACLConfig, now time.Time, horizon time.Duration) []acl.CIDREntry {
+	var expiring []acl.CIDREntry
+	for _, rule := range config.AllRules() {
+		for _, entry := range rule.CIDREntries {
+			if entry.ExpiresAt != nil && !entry.IsExpired(now) && entry.ExpiresAt.Sub(now) <= horizon {
+				expiring = append(expiring, entry)
+			}
+		}
+	}
+	slices.SortStableFunc(expiring, func(a, b acl.CIDREntry) int {
+		return a.ExpiresAt.Compare(b.ExpiresAt.Time)
+	})
+	return expiring
+}
@@ -0,0 +1,101 @@
+package helper
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("CIDR entries", func() {
+	var (
+		now      time.Time
+		inAnHour *metav1.Time
+		inADay   *metav1.Time
+		expired  *metav1.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		inAnHour = &metav1.Time{Time: now.Add(time.Hour)}
+		inADay = &metav1.Time{Time: now.Add(24 * time.Hour)}
+		expired = &metav1.Time{Time: now}
+	})
+
+	Describe("#ResolveCIDREntries", func() {
+		It("should add the CIDRs of the unexpired entries to the rules", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}, CIDREntries: []acl.CIDREntry{
+						{CIDR: "203.0.113.0/24", ExpiresAt: inADay},
+						{CIDR: "198.51.100.0/24", ExpiresAt: expired},
+						{CIDR: "192.0.2.0/24"},
+					}},
+				},
+				VPN: &acl.EndpointConfig{
+					Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDREntries: []acl.CIDREntry{{CIDR: "192.0.2.1/32", ExpiresAt: inAnHour}}},
+				},
+			}
+
+			Expect(ResolveCIDREntries(config, now)).To(Equal(inAnHour.Time))
+
+			Expect(config).To(Equal(&acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "192.0.2.0/24", "203.0.113.0/24"}},
+				},
+				VPN: &acl.EndpointConfig{
+					Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"192.0.2.1/32"}},
+				},
+			}))
+		})
+
+		It("should remove rules left without CIDRs", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", CIDREntries: []acl.CIDREntry{{CIDR: "10.1.2.3/32", ExpiresAt: expired}}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+				},
+				APIServer: &acl.EndpointConfig{
+					Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDREntries: []acl.CIDREntry{{CIDR: "192.0.2.1/32", ExpiresAt: expired}}},
+				},
+			}
+
+			Expect(ResolveCIDREntries(config, now)).To(BeZero())
+
+			Expect(config).To(Equal(&acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+				},
+				APIServer: &acl.EndpointConfig{
+					Rules: []acl.ACLRule{{Action: "DENY", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0", "::/0"}}},
+				},
+			}))
+		})
+	})
+
+	Describe("#ExpiringCIDREntries", func() {
+		It("should return the entries expiring within the horizon ordered by their expiry", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDREntries: []acl.CIDREntry{
+					{CIDR: "203.0.113.0/24", ExpiresAt: inADay, Owner: "team-a"},
+					{CIDR: "198.51.100.0/24", ExpiresAt: expired},
+					{CIDR: "192.0.2.0/24"},
+					{CIDR: "192.0.2.1/32", ExpiresAt: &metav1.Time{Time: now.Add(48 * time.Hour)}},
+				}},
+				HTTPProxy: &acl.EndpointConfig{
+					Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDREntries: []acl.CIDREntry{{CIDR: "10.0.0.0/8", ExpiresAt: inAnHour}}},
+				},
+			}
+
+			Expect(ExpiringCIDREntries(config, now, 24*time.Hour)).To(Equal([]acl.CIDREntry{
+				{CIDR: "10.0.0.0/8", ExpiresAt: inAnHour},
+				{CIDR: "203.0.113.0/24", ExpiresAt: inADay, Owner: "team-a"},
+			}))
+		})
+	})
+})
@@ -0,0 +1,100 @@
+package helper
+
+import (
+	"net/netip"
+	"slices"
+)
+
+// CIDRSet is a set of IPv4 and IPv6 prefixes. It keeps the prefixes in their
+// minimal form: duplicates and prefixes covered by other prefixes are removed
+// and adjacent prefixes are merged into their common parent prefix, e.g.
+// 10.0.0.0/25 and 10.0.0.128/25 become 10.0.0.0/24. The set matches exactly the
+// addresses matched by the inserted prefixes, so it can be used to reduce the
+// number of principals an RBAC filter has to evaluate.
+//
+// The zero value is an empty set ready to use.
+type CIDRSet struct {
+	prefixes []netip.Prefix
+	dirty    bool
+}
+
+// NewCIDRSet returns a new CIDRSet containing the given CIDRs, see InsertCIDRs.
+func NewCIDRSet(cidrs ...string) *CIDRSet {
+	s := &CIDRSet{}
+	s.InsertCIDRs(cidrs...)
+	return s
+}
+
+// Insert adds the given prefixes to the set in their canonical form, see
+// CanonicalPrefix. Invalid prefixes are skipped.
+func (s *CIDRSet) Insert(prefixes ...netip.Prefix) {
+	for _, prefix := range prefixes {
+		if !prefix.IsValid() {
+			continue
+		}
+		s.prefixes = append(s.prefixes, CanonicalPrefix(prefix))
+		s.dirty = true
+	}
+}
+
+// InsertCIDRs parses the given strings in the form "10.0.0.0/8" and adds them to
+// the set in their canonical form. Invalid CIDRs are skipped.
+func (s *CIDRSet) InsertCIDRs(cidrs ...string) {
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		s.Insert(prefix)
+	}
+}
+
+// Prefixes returns the minimized list of prefixes of the set. IPv4 prefixes are
+// sorted before IPv6 prefixes, both by address.
+func (s *CIDRSet) Prefixes() []netip.Prefix {
+	if s.dirty {
+		s.prefixes = minimizePrefixes(s.prefixes)
+		s.dirty = false
+	}
+	return slices.Clone(s.prefixes)
+}
+
+// minimizePrefixes sorts the given masked prefixes, removes the ones covered by
+// other prefixes and merges adjacent ones.
+func minimizePrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	slices.SortFunc(prefixes, ComparePrefixes)
+
+	minimized := make([]netip.Prefix, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		// thanks to the sort order, a covering prefix always precedes the
+		// prefixes it covers
+		if n := len(minimized); n > 0 && minimized[n-1].Overlaps(prefix) {
+			continue
+		}
+		minimized = append(minimized, prefix)
+
+		// merging two prefixes might create a prefix adjacent to its
+		// predecessor, so merge as long as possible
+		for n := len(minimized); n > 1; n = len(minimized) {
+			parent, ok := mergeAdjacent(minimized[n-2], minimized[n-1])
+			if !ok {
+				break
+			}
+			minimized = append(minimized[:n-2], parent)
+		}
+	}
+	return minimized
+}
+
+// mergeAdjacent returns the parent prefix of a and b if they are the two halves
+// of it.
+func mergeAdjacent(a, b netip.Prefix) (netip.Prefix, bool) {
+	if a.Bits() != b.Bits() || a.Bits() == 0 || a.Addr().BitLen() != b.Addr().BitLen() || a == b {
+		return netip.Prefix{}, false
+	}
+	parent := netip.PrefixFrom(a.Addr(), a.Bits()-1).Masked()
+	if parent != netip.PrefixFrom(b.Addr(), b.Bits()-1).Masked() {
+		return netip.Prefix{}, false
+	}
+	return parent, true
+}
@@ -0,0 +1,65 @@
+package helper
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CIDRSet", func() {
+	prefixes := func(cidrs ...string) []netip.Prefix {
+		result := make([]netip.Prefix, 0, len(cidrs))
+		for _, cidr := range cidrs {
+			result = append(result, netip.MustParsePrefix(cidr))
+		}
+		return result
+	}
+
+	It("should be empty by default", func() {
+		Expect((&CIDRSet{}).Prefixes()).To(BeEmpty())
+	})
+
+	It("should skip invalid CIDRs", func() {
+		Expect(NewCIDRSet("foo", "10.0.0.0/33", "10.0.0.0").Prefixes()).To(BeEmpty())
+	})
+
+	It("should mask host bits", func() {
+		Expect(NewCIDRSet("10.1.2.3/16", "2001:db8::1/32").Prefixes()).To(Equal(prefixes("10.1.0.0/16", "2001:db8::/32")))
+	})
+
+	It("should convert IPv4-mapped IPv6 prefixes to IPv4 prefixes", func() {
+		Expect(NewCIDRSet("::ffff:10.0.0.0/104", "10.0.0.0/8", "::ffff:192.168.1.1/128").Prefixes()).
+			To(Equal(prefixes("10.0.0.0/8", "192.168.1.1/32")))
+	})
+
+	It("should remove duplicates and sort the prefixes", func() {
+		Expect(NewCIDRSet("2001:db8::/32", "192.168.0.0/16", "10.0.0.0/8", "192.168.0.0/16", "2001:db8::/32").Prefixes()).
+			To(Equal(prefixes("10.0.0.0/8", "192.168.0.0/16", "2001:db8::/32")))
+	})
+
+	It("should remove subsumed prefixes", func() {
+		Expect(NewCIDRSet("10.1.2.3/32", "10.1.0.0/16", "10.0.0.0/8", "2001:db8:1::/48", "2001:db8::/32").Prefixes()).
+			To(Equal(prefixes("10.0.0.0/8", "2001:db8::/32")))
+	})
+
+	It("should merge adjacent prefixes", func() {
+		Expect(NewCIDRSet("10.0.0.0/25", "10.0.0.128/25", "10.0.1.0/24", "2001:db8::/33", "2001:db8:8000::/33").Prefixes()).
+			To(Equal(prefixes("10.0.0.0/23", "2001:db8::/32")))
+	})
+
+	It("should not merge prefixes which are not halves of the same parent", func() {
+		Expect(NewCIDRSet("10.0.1.0/24", "10.0.2.0/24").Prefixes()).To(Equal(prefixes("10.0.1.0/24", "10.0.2.0/24")))
+	})
+
+	It("should not merge prefixes of different address families", func() {
+		Expect(NewCIDRSet("0.0.0.0/0", "::/0").Prefixes()).To(Equal(prefixes("0.0.0.0/0", "::/0")))
+	})
+
+	It("should merge prefixes inserted in multiple steps", func() {
+		set := NewCIDRSet("10.0.0.0/24")
+		Expect(set.Prefixes()).To(Equal(prefixes("10.0.0.0/24")))
+		set.Insert(prefixes("10.0.1.0/24", "10.0.2.0/23")...)
+		Expect(set.Prefixes()).To(Equal(prefixes("10.0.0.0/22")))
+	})
+})
@@ -0,0 +1,57 @@
+package helper
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+	"unicode"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+// ResolveCIDRSets adds the CIDRs of the CIDR sets referenced by the rules of the
+// given ACLConfig to the CIDRs of the rules and removes the references, so the
+// rules can be rendered like rules without CIDR sets. The resulting CIDRs are
+// normalized with NormalizeCIDRs. The config is modified in place. All
+// referenced sets must be contained in cidrSets.
+func ResolveCIDRSets(config *acl.ACLConfig, cidrSets map[string][]string) error {
+	var unknown []string
+	for _, name := range config.AllCIDRSets() {
+		if _, ok := cidrSets[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown CIDR sets: %s", strings.Join(unknown, ", "))
+	}
+
+	forEachRule(config, func(rule *acl.ACLRule) {
+		if len(rule.CIDRSets) == 0 {
+			return
+		}
+		for _, name := range rule.CIDRSets {
+			rule.Cidrs = append(rule.Cidrs, cidrSets[name]...)
+		}
+		rule.Cidrs = NormalizeCIDRs(rule.Cidrs)
+		rule.CIDRSets = nil
+	})
+	return nil
+}
+
+// ParseCIDRList parses a list of CIDRs separated by commas or whitespace, as
+// stored in CIDR sets and in resources referenced with cidrsFrom. The list must
+// not be empty and all CIDRs must be valid.
+func ParseCIDRList(value string) ([]string, error) {
+	cidrs := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	if len(cidrs) == 0 {
+		return nil, fmt.Errorf("no CIDRs found")
+	}
+	for _, cidr := range cidrs {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			return nil, fmt.Errorf("invalid CIDR: %w", err)
+		}
+	}
+	return cidrs, nil
+}
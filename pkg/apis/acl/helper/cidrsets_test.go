@@ -0,0 +1,80 @@
+package helper
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("cidrsets", func() {
+	var cidrSets map[string][]string
+
+	BeforeEach(func() {
+		cidrSets = map[string][]string{
+			"corporate-vpn": {"11.12.13.0/24", "14.15.16.17/32"},
+			"ci-runners":    {"21.22.23.0/24", "11.12.13.0/24"},
+		}
+	})
+
+	Describe("#ResolveCIDRSets", func() {
+		It("should add the CIDRs of the referenced sets to all rules", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}, CIDRSets: []string{"corporate-vpn"}},
+				Ingress: &acl.IngressConfig{
+					Components: map[string]acl.EndpointConfig{
+						acl.IngressComponentPlutono: {Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDRSets: []string{"ci-runners", "corporate-vpn"}}},
+					},
+				},
+			}
+
+			Expect(ResolveCIDRSets(config, cidrSets)).To(Succeed())
+
+			Expect(config).To(Equal(&acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "11.12.13.0/24", "14.15.16.17/32"}},
+				Ingress: &acl.IngressConfig{
+					Components: map[string]acl.EndpointConfig{
+						acl.IngressComponentPlutono: {Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"11.12.13.0/24", "14.15.16.17/32", "21.22.23.0/24"}}},
+					},
+				},
+			}))
+		})
+
+		It("should not modify rules without CIDR sets", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.1.0.0/8"}},
+			}
+
+			Expect(ResolveCIDRSets(config, nil)).To(Succeed())
+
+			Expect(config.Rule.Cidrs).To(Equal([]string{"10.1.0.0/8"}))
+		})
+
+		It("should return all unknown sets", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "ALLOW", Type: "remote_ip", CIDRSets: []string{"office", "corporate-vpn"}},
+					{Action: "ALLOW", Type: "remote_ip", CIDRSets: []string{"home"}},
+				},
+			}
+
+			Expect(ResolveCIDRSets(config, cidrSets)).To(MatchError("unknown CIDR sets: home, office"))
+		})
+	})
+
+	Describe("#ParseCIDRList", func() {
+		It("should split the CIDRs at commas and whitespace", func() {
+			Expect(ParseCIDRList("10.0.0.0/8, 172.16.0.0/12\n192.168.0.0/16\t::1/128\n")).To(Equal([]string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "::1/128"}))
+		})
+
+		It("should return an error for invalid CIDRs", func() {
+			_, err := ParseCIDRList("10.0.0.0/8,10.0.0.1")
+			Expect(err).To(MatchError(ContainSubstring("invalid CIDR")))
+		})
+
+		It("should return an error for an empty list", func() {
+			_, err := ParseCIDRList(" ,\n")
+			Expect(err).To(MatchError("no CIDRs found"))
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+// ResolveCIDRsFrom adds the CIDRs read from the resources referenced by the
+// rules of the given ACLConfig to the CIDRs of the rules and removes the
+// references, see ResolveCIDRSets. All references must be contained in
+// cidrsFrom.
+func ResolveCIDRsFrom(config *acl.ACLConfig, cidrsFrom map[acl.CIDRsFromReference][]string) error {
+	var unresolved []string
+	for _, ref := range config.AllCIDRsFrom() {
+		if _, ok := cidrsFrom[ref]; !ok {
+			unresolved = append(unresolved, ref.ResourceName+"/"+ref.Key)
+		}
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("unresolved cidrsFrom references: %s", strings.Join(unresolved, ", "))
+	}
+
+	forEachRule(config, func(rule *acl.ACLRule) {
+		if rule.CIDRsFrom == nil {
+			return
+		}
+		rule.Cidrs = NormalizeCIDRs(append(rule.Cidrs, cidrsFrom[*rule.CIDRsFrom]...))
+		rule.CIDRsFrom = nil
+	})
+	return nil
+}
@@ -0,0 +1,50 @@
+package helper
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("cidrsfrom", func() {
+	Describe("#ResolveCIDRsFrom", func() {
+		var (
+			ref       acl.CIDRsFromReference
+			cidrsFrom map[acl.CIDRsFromReference][]string
+		)
+
+		BeforeEach(func() {
+			ref = acl.CIDRsFromReference{ResourceName: "acl-cidrs", Key: "cidrs"}
+			cidrsFrom = map[acl.CIDRsFromReference][]string{
+				ref: {"11.12.13.0/24", "10.0.0.0/8"},
+			}
+		})
+
+		It("should add the CIDRs of the referenced resources to all rules", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}, CIDRsFrom: &ref},
+				},
+			}
+
+			Expect(ResolveCIDRsFrom(config, cidrsFrom)).To(Succeed())
+
+			Expect(config).To(Equal(&acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "11.12.13.0/24"}},
+				},
+			}))
+		})
+
+		It("should return all unresolved references", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDRsFrom: &acl.CIDRsFromReference{ResourceName: "acl-cidrs", Key: "ipv6"}},
+			}
+
+			Expect(ResolveCIDRsFrom(config, cidrsFrom)).To(MatchError("unresolved cidrsFrom references: acl-cidrs/ipv6"))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package helper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+// ResolveGeoIP adds the CIDRs of the countries and ASNs of the rules of the
+// given ACLConfig to the CIDRs of the rules and removes the countries and ASNs,
+// see ResolveCIDRSets. All countries must be contained in countryCIDRs and all
+// ASNs in asnCIDRs.
+func ResolveGeoIP(config *acl.ACLConfig, countryCIDRs map[string][]string, asnCIDRs map[uint32][]string) error {
+	var unresolved []string
+	for _, country := range config.AllCountries() {
+		if _, ok := countryCIDRs[country]; !ok {
+			unresolved = append(unresolved, country)
+		}
+	}
+	for _, asn := range config.AllASNs() {
+		if _, ok := asnCIDRs[asn]; !ok {
+			unresolved = append(unresolved, "AS"+strconv.FormatUint(uint64(asn), 10))
+		}
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("unknown countries or ASNs: %s", strings.Join(unresolved, ", "))
+	}
+
+	forEachRule(config, func(rule *acl.ACLRule) {
+		if len(rule.Countries) == 0 && len(rule.ASNs) == 0 {
+			return
+		}
+		for _, country := range rule.Countries {
+			rule.Cidrs = append(rule.Cidrs, countryCIDRs[country]...)
+		}
+		for _, asn := range rule.ASNs {
+			rule.Cidrs = append(rule.Cidrs, asnCIDRs[asn]...)
+		}
+		rule.Cidrs = NormalizeCIDRs(rule.Cidrs)
+		rule.Countries = nil
+		rule.ASNs = nil
+	})
+	return nil
+}
@@ -0,0 +1,48 @@
+package helper
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("geoip", func() {
+	Describe("#ResolveGeoIP", func() {
+		var (
+			countryCIDRs map[string][]string
+			asnCIDRs     map[uint32][]string
+		)
+
+		BeforeEach(func() {
+			countryCIDRs = map[string][]string{"DE": {"11.0.0.0/8"}, "FR": {"12.0.0.0/8"}}
+			asnCIDRs = map[uint32][]string{64496: {"13.1.0.0/16"}}
+		})
+
+		It("should add the CIDRs of the countries and ASNs to all rules", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}, Countries: []string{"DE", "FR"}, ASNs: []uint32{64496}},
+				},
+			}
+
+			Expect(ResolveGeoIP(config, countryCIDRs, asnCIDRs)).To(Succeed())
+
+			Expect(config).To(Equal(&acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "11.0.0.0/8", "12.0.0.0/8", "13.1.0.0/16"}},
+				},
+			}))
+		})
+
+		It("should return all unknown countries and ASNs", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Countries: []string{"DE", "AT"}, ASNs: []uint32{64497, 64496}},
+			}
+
+			Expect(ResolveGeoIP(config, countryCIDRs, asnCIDRs)).To(MatchError("unknown countries or ASNs: AT, AS64497"))
+		})
+	})
+})
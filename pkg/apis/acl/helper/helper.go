@@ -0,0 +1,25 @@
+package helper
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclv1alpha1 "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/v1alpha1"
+)
+
+// DecodeACLConfig decodes the providerConfig of the acl extension into the
+// internal ACLConfig. The decoder is expected to be strict, so unknown fields
+// are rejected. For backwards compatibility, configs without apiVersion and
+// kind are decoded as v1alpha1 ACLConfig.
+func DecodeACLConfig(decoder runtime.Decoder, providerConfig *runtime.RawExtension) (*acl.ACLConfig, error) {
+	config := &acl.ACLConfig{}
+	if providerConfig == nil || providerConfig.Raw == nil {
+		return config, nil
+	}
+
+	defaultGVK := aclv1alpha1.SchemeGroupVersion.WithKind("ACLConfig")
+	if _, _, err := decoder.Decode(providerConfig.Raw, &defaultGVK, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
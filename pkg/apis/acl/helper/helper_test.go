@@ -0,0 +1,61 @@
+package helper
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/install"
+)
+
+var _ = Describe("helper", func() {
+	Describe("#DecodeACLConfig", func() {
+		var decoder runtime.Decoder
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			install.Install(scheme)
+			decoder = serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
+		})
+
+		It("should return an empty config if no provider config is given", func() {
+			Expect(DecodeACLConfig(decoder, nil)).To(Equal(&acl.ACLConfig{}))
+		})
+
+		It("should decode a config with apiVersion and kind", func() {
+			config, err := DecodeACLConfig(decoder, &runtime.RawExtension{Raw: []byte(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"ACLConfig","rule":{"action":"ALLOW","type":"source_ip","cidrs":["10.0.0.0/8"]}}`)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Rule).To(Equal(&acl.ACLRule{Action: "ALLOW", Type: "source_ip", Cidrs: []string{"10.0.0.0/8"}}))
+		})
+
+		It("should decode a legacy config without apiVersion and kind", func() {
+			config, err := DecodeACLConfig(decoder, &runtime.RawExtension{Raw: []byte(`{"rules":[{"action":"DENY","type":"remote_ip","cidrs":["10.0.0.0/8"]}]}`)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Rules).To(Equal([]acl.ACLRule{{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}}}))
+		})
+
+		It("should default the rule type", func() {
+			config, err := DecodeACLConfig(decoder, &runtime.RawExtension{Raw: []byte(`{"ingress":{"components":{"plutono":{"rule":{"action":"ALLOW","cidrs":["0.0.0.0/0"]}}}}}`)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Ingress.Components["plutono"].Rule.Type).To(Equal("remote_ip"))
+		})
+
+		It("should default the location of schedules", func() {
+			config, err := DecodeACLConfig(decoder, &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["0.0.0.0/0"],"schedule":{"maintenanceWindow":true}}}`)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Rule.Schedule).To(Equal(&acl.Schedule{MaintenanceWindow: true, Location: "UTC"}))
+		})
+
+		It("should reject unknown fields", func() {
+			_, err := DecodeACLConfig(decoder, &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidr":["10.0.0.0/8"]}}`)})
+			Expect(err).To(MatchError(ContainSubstring(`unknown field "rule.cidr"`)))
+		})
+
+		It("should reject unknown kinds", func() {
+			_, err := DecodeACLConfig(decoder, &runtime.RawExtension{Raw: []byte(`{"apiVersion":"acl.extensions.gardener.cloud/v1alpha1","kind":"Foo"}`)})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,35 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+// ResolveHosts adds the CIDRs of the hosts of the rules of the given ACLConfig
+// to the CIDRs of the rules and removes the hosts, see ResolveCIDRSets. All
+// hosts must be contained in hostCIDRs.
+func ResolveHosts(config *acl.ACLConfig, hostCIDRs map[string][]string) error {
+	var unresolved []string
+	for _, host := range config.AllHosts() {
+		if _, ok := hostCIDRs[host]; !ok {
+			unresolved = append(unresolved, host)
+		}
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("unresolved hosts: %s", strings.Join(unresolved, ", "))
+	}
+
+	forEachRule(config, func(rule *acl.ACLRule) {
+		if len(rule.Hosts) == 0 {
+			return
+		}
+		for _, host := range rule.Hosts {
+			rule.Cidrs = append(rule.Cidrs, hostCIDRs[host]...)
+		}
+		rule.Cidrs = NormalizeCIDRs(rule.Cidrs)
+		rule.Hosts = nil
+	})
+	return nil
+}
@@ -0,0 +1,46 @@
+package helper
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("hosts", func() {
+	Describe("#ResolveHosts", func() {
+		var hostCIDRs map[string][]string
+
+		BeforeEach(func() {
+			hostCIDRs = map[string][]string{
+				"office.example.com": {"11.12.13.14/32", "2001:db8::1/128"},
+			}
+		})
+
+		It("should add the CIDRs of the hosts to all rules", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}, Hosts: []string{"office.example.com"}},
+				},
+			}
+
+			Expect(ResolveHosts(config, hostCIDRs)).To(Succeed())
+
+			Expect(config).To(Equal(&acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "11.12.13.14/32", "2001:db8::1/128"}},
+				},
+			}))
+		})
+
+		It("should return all unresolved hosts", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Hosts: []string{"vpn.example.com", "office.example.com", "ci.example.com"}},
+			}
+
+			Expect(ResolveHosts(config, hostCIDRs)).To(MatchError("unresolved hosts: ci.example.com, vpn.example.com"))
+		})
+	})
+})
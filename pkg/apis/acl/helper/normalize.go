@@ -0,0 +1,141 @@
+package helper
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+// NormalizeACLConfig canonicalizes all rules of the given ACLConfig in place,
+// see NormalizeACLRule.
+func NormalizeACLConfig(config *acl.ACLConfig) {
+	forEachRule(config, NormalizeACLRule)
+}
+
+// forEachRule calls fn for all rules of the given ACLConfig, including the rules
+// of all endpoint and component overrides. fn may modify the rules in place.
+func forEachRule(config *acl.ACLConfig, fn func(rule *acl.ACLRule)) {
+	visit := func(rule *acl.ACLRule, rules []acl.ACLRule) {
+		if rule != nil {
+			fn(rule)
+		}
+		for i := range rules {
+			fn(&rules[i])
+		}
+	}
+
+	visit(config.Rule, config.Rules)
+	for _, endpoint := range config.Endpoints() {
+		visit(endpoint.Rule, endpoint.Rules)
+	}
+	if config.Ingress != nil {
+		for name, component := range config.Ingress.Components {
+			visit(component.Rule, component.Rules)
+			config.Ingress.Components[name] = component
+		}
+	}
+}
+
+// NormalizeACLRule canonicalizes the given rule in place: the action is
+// upper-cased, the type is lower-cased, the CIDRs are normalized with
+// NormalizeCIDRs, the CIDRs of the CIDR entries are converted to their canonical
+// form and the CIDR sets, the lower-cased hosts, the upper-cased country codes
+// and the ASNs are deduplicated and sorted.
+func NormalizeACLRule(rule *acl.ACLRule) {
+	rule.Action = strings.ToUpper(rule.Action)
+	rule.Type = strings.ToLower(rule.Type)
+	rule.Cidrs = NormalizeCIDRs(rule.Cidrs)
+	for i := range rule.CIDREntries {
+		if prefix, err := ParseCanonicalPrefix(rule.CIDREntries[i].CIDR); err == nil {
+			rule.CIDREntries[i].CIDR = prefix.String()
+		}
+	}
+	if rule.CIDRSets != nil {
+		slices.Sort(rule.CIDRSets)
+		rule.CIDRSets = slices.Compact(rule.CIDRSets)
+	}
+	if rule.Hosts != nil {
+		for i := range rule.Hosts {
+			rule.Hosts[i] = strings.ToLower(rule.Hosts[i])
+		}
+		slices.Sort(rule.Hosts)
+		rule.Hosts = slices.Compact(rule.Hosts)
+	}
+	if rule.Countries != nil {
+		for i := range rule.Countries {
+			rule.Countries[i] = strings.ToUpper(rule.Countries[i])
+		}
+		slices.Sort(rule.Countries)
+		rule.Countries = slices.Compact(rule.Countries)
+	}
+	if rule.ASNs != nil {
+		slices.Sort(rule.ASNs)
+		rule.ASNs = slices.Compact(rule.ASNs)
+	}
+}
+
+// NormalizeCIDRs converts the given CIDRs to their canonical form (see
+// CanonicalPrefix), removes duplicates and sorts them by address family,
+// address and prefix length. Entries which are not valid CIDRs
+// are kept in their original order after the valid ones, so they are still
+// reported by the validation.
+func NormalizeCIDRs(cidrs []string) []string {
+	if cidrs == nil {
+		return nil
+	}
+
+	var (
+		prefixes = make([]netip.Prefix, 0, len(cidrs))
+		invalid  []string
+	)
+	for _, cidr := range cidrs {
+		prefix, err := ParseCanonicalPrefix(cidr)
+		if err != nil {
+			invalid = append(invalid, cidr)
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	slices.SortFunc(prefixes, ComparePrefixes)
+	prefixes = slices.Compact(prefixes)
+
+	normalized := make([]string, 0, len(prefixes)+len(invalid))
+	for _, prefix := range prefixes {
+		normalized = append(normalized, prefix.String())
+	}
+	return append(normalized, invalid...)
+}
+
+// ParseCanonicalPrefix parses a CIDR in the form "10.0.0.0/8" and returns its
+// canonical form, see CanonicalPrefix.
+func ParseCanonicalPrefix(cidr string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return CanonicalPrefix(prefix), nil
+}
+
+// CanonicalPrefix returns the canonical form of the given prefix, so the same
+// range of addresses is always written the same way: the host bits are cleared
+// (e.g. "10.1.2.3/8" becomes "10.0.0.0/8") and IPv4-mapped IPv6 prefixes only
+// covering IPv4-mapped addresses are converted to IPv4 prefixes (e.g.
+// "::ffff:10.0.0.0/104" becomes "10.0.0.0/8").
+func CanonicalPrefix(prefix netip.Prefix) netip.Prefix {
+	if addr := prefix.Addr(); addr.Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked()
+}
+
+// ComparePrefixes compares two prefixes by address family, address and prefix
+// length. It can be used with slices.SortFunc.
+func ComparePrefixes(a, b netip.Prefix) int {
+	if c := a.Addr().Compare(b.Addr()); c != 0 {
+		return c
+	}
+	return a.Bits() - b.Bits()
+}
@@ -0,0 +1,91 @@
+package helper
+
+import (
+	"net/netip"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("normalize", func() {
+	Describe("#NormalizeCIDRs", func() {
+		It("should return nil for nil", func() {
+			Expect(NormalizeCIDRs(nil)).To(BeNil())
+		})
+
+		It("should convert host addresses to network addresses", func() {
+			Expect(NormalizeCIDRs([]string{"10.1.2.3/8", "2001:db8::1/32"})).To(Equal([]string{"10.0.0.0/8", "2001:db8::/32"}))
+		})
+
+		It("should remove duplicates and sort the CIDRs", func() {
+			Expect(NormalizeCIDRs([]string{
+				"2001:db8::/32",
+				"192.168.0.0/16",
+				"10.0.0.0/16",
+				"10.0.0.0/8",
+				"10.0.0.1/16",
+				"9.0.0.0/8",
+			})).To(Equal([]string{
+				"9.0.0.0/8",
+				"10.0.0.0/8",
+				"10.0.0.0/16",
+				"192.168.0.0/16",
+				"2001:db8::/32",
+			}))
+		})
+
+		It("should convert IPv4-mapped IPv6 CIDRs to IPv4 CIDRs", func() {
+			Expect(NormalizeCIDRs([]string{"::ffff:10.1.2.3/104", "10.0.0.0/8"})).To(Equal([]string{"10.0.0.0/8"}))
+		})
+
+		It("should keep invalid CIDRs after the valid ones", func() {
+			Expect(NormalizeCIDRs([]string{"foo", "10.0.0.1/32", "10.0.0.1"})).To(Equal([]string{"10.0.0.1/32", "foo", "10.0.0.1"}))
+		})
+	})
+
+	DescribeTable("#CanonicalPrefix",
+		func(prefix, expected string) {
+			Expect(CanonicalPrefix(netip.MustParsePrefix(prefix))).To(Equal(netip.MustParsePrefix(expected)))
+		},
+		Entry("canonical IPv4 prefix", "10.0.0.0/8", "10.0.0.0/8"),
+		Entry("IPv4 prefix with host bits", "10.1.2.3/16", "10.1.0.0/16"),
+		Entry("canonical IPv6 prefix", "2001:db8::/32", "2001:db8::/32"),
+		Entry("IPv6 prefix with host bits", "2001:db8::1/64", "2001:db8::/64"),
+		Entry("IPv4-mapped IPv6 prefix", "::ffff:10.1.2.3/104", "10.0.0.0/8"),
+		Entry("IPv4-mapped IPv6 host", "::ffff:10.1.2.3/128", "10.1.2.3/32"),
+		Entry("IPv6 prefix covering all IPv4-mapped addresses", "::ffff:0.0.0.0/96", "0.0.0.0/0"),
+		Entry("IPv6 prefix covering more than the IPv4-mapped addresses", "::ffff:0.0.0.0/80", "::/80"),
+	)
+
+	Describe("#NormalizeACLConfig", func() {
+		It("should normalize all rules of the config", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "allow", Type: "Remote_IP", Cidrs: []string{"10.1.0.0/8"}, Hosts: []string{"VPN.example.com", "office.example.com", "vpn.example.com"}, Countries: []string{"fr", "DE", "FR"}, ASNs: []uint32{64497, 64496, 64497}},
+				APIServer: &acl.EndpointConfig{
+					Rules: []acl.ACLRule{{Action: "deny", Type: "SOURCE_IP", Cidrs: []string{"10.0.0.2/24", "10.0.0.1/24"}, CIDREntries: []acl.CIDREntry{{CIDR: "10.0.1.1/24", Owner: "team-a"}}}},
+				},
+				Ingress: &acl.IngressConfig{
+					Components: map[string]acl.EndpointConfig{
+						acl.IngressComponentPlutono: {Rule: &acl.ACLRule{Action: "Allow", Type: "remote_ip", Cidrs: []string{"172.16.1.1/12"}}},
+					},
+				},
+			}
+
+			NormalizeACLConfig(config)
+
+			Expect(config).To(Equal(&acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}, Hosts: []string{"office.example.com", "vpn.example.com"}, Countries: []string{"DE", "FR"}, ASNs: []uint32{64496, 64497}},
+				APIServer: &acl.EndpointConfig{
+					Rules: []acl.ACLRule{{Action: "DENY", Type: "source_ip", Cidrs: []string{"10.0.0.0/24"}, CIDREntries: []acl.CIDREntry{{CIDR: "10.0.1.0/24", Owner: "team-a"}}}},
+				},
+				Ingress: &acl.IngressConfig{
+					Components: map[string]acl.EndpointConfig{
+						acl.IngressComponentPlutono: {Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"172.16.0.0/12"}}},
+					},
+				},
+			}))
+		})
+	})
+})
@@ -0,0 +1,205 @@
+package helper
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var weekdays = map[string]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// MaintenanceWindow is the maintenance time window of a shoot in the format of
+// the Gardener API, e.g. "220000+0100".
+type MaintenanceWindow struct {
+	Begin string
+	End   string
+}
+
+// ParseWeekday parses an abbreviated weekday, e.g. "Mon".
+func ParseWeekday(day string) (time.Weekday, error) {
+	weekday, ok := weekdays[day]
+	if !ok {
+		return 0, fmt.Errorf("invalid weekday %q, expected one of Mon, Tue, Wed, Thu, Fri, Sat, Sun", day)
+	}
+	return weekday, nil
+}
+
+// ParseTimeOfDay parses a time of day in the form "15:04".
+func ParseTimeOfDay(value string) (time.Time, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return t, nil
+}
+
+// ApplySchedules removes the rules of the given ACLConfig whose schedule is
+// not active at now. If none of the rules of a list is active, the list is
+// replaced by a rule keeping its effect on addresses not matched by any rule:
+// a list containing ALLOW rules denies all addresses, a list of DENY rules
+// allows all addresses. It returns the time the next schedule starts or ends,
+// or the zero time if no rule has a schedule.
+func ApplySchedules(config *acl.ACLConfig, now time.Time, maintenance *MaintenanceWindow) (time.Time, error) {
+	var (
+		next time.Time
+		errs []error
+	)
+	filterRules(config, func(rule *acl.ACLRule) bool {
+		if rule.Schedule == nil {
+			return true
+		}
+		isActive, transition, err := evaluateSchedule(rule.Schedule, now, maintenance)
+		if err != nil {
+			errs = append(errs, err)
+			return false
+		}
+		if next.IsZero() || (!transition.IsZero() && transition.Before(next)) {
+			next = transition
+		}
+		return isActive
+	})
+	return next, errors.Join(errs...)
+}
+
+// filterRules removes the rules of the given ACLConfig for which keep returns
+// false. If none of the rules of a list is kept, the list is replaced by
+// inactiveRule.
+func filterRules(config *acl.ACLConfig, keep func(rule *acl.ACLRule) bool) {
+	filter := func(rule **acl.ACLRule, rules *[]acl.ACLRule) {
+		all := *rules
+		if *rule != nil {
+			all = []acl.ACLRule{**rule}
+		}
+
+		var kept []acl.ACLRule
+		for i := range all {
+			if keep(&all[i]) {
+				kept = append(kept, all[i])
+			}
+		}
+		if len(all) == 0 || len(kept) == len(all) {
+			return
+		}
+		if len(kept) == 0 {
+			kept = []acl.ACLRule{inactiveRule(all)}
+		}
+		*rule, *rules = nil, kept
+	}
+
+	filter(&config.Rule, &config.Rules)
+	for _, endpoint := range config.Endpoints() {
+		filter(&endpoint.Rule, &endpoint.Rules)
+	}
+	if config.Ingress != nil {
+		for name, component := range config.Ingress.Components {
+			filter(&component.Rule, &component.Rules)
+			config.Ingress.Components[name] = component
+		}
+	}
+}
+
+// inactiveRule returns the rule replacing the given list of rules if none of
+// them is active.
+func inactiveRule(rules []acl.ACLRule) acl.ACLRule {
+	action := "ALLOW"
+	for _, rule := range rules {
+		if strings.EqualFold(rule.Action, "ALLOW") {
+			action = "DENY"
+			break
+		}
+	}
+	return acl.ACLRule{Action: action, Type: "remote_ip", Cidrs: []string{"0.0.0.0/0", "::/0"}}
+}
+
+// evaluateSchedule returns whether the given schedule is active at now and
+// the time it starts or ends next.
+func evaluateSchedule(schedule *acl.Schedule, now time.Time, maintenance *MaintenanceWindow) (bool, time.Time, error) {
+	var intervals [][2]time.Time
+
+	location, err := time.LoadLocation(cmp.Or(schedule.Location, "UTC"))
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid location %q: %w", schedule.Location, err)
+	}
+	for _, window := range schedule.Windows {
+		start, err := ParseTimeOfDay(window.Start)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		end, err := ParseTimeOfDay(window.End)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		days := map[time.Weekday]bool{}
+		for _, day := range window.Days {
+			weekday, err := ParseWeekday(day)
+			if err != nil {
+				return false, time.Time{}, err
+			}
+			days[weekday] = true
+		}
+		intervals = append(intervals, dailyIntervals(now.In(location), start, end, days)...)
+	}
+
+	if schedule.MaintenanceWindow {
+		if maintenance == nil {
+			return false, time.Time{}, errors.New("the shoot has no maintenance time window")
+		}
+		begin, err := time.Parse("150405-0700", maintenance.Begin)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid maintenance time window begin %q: %w", maintenance.Begin, err)
+		}
+		end, err := time.Parse("150405-0700", maintenance.End)
+		if err != nil {
+			return false, time.Time{}, fmt.Errorf("invalid maintenance time window end %q: %w", maintenance.End, err)
+		}
+		intervals = append(intervals, dailyIntervals(now.In(begin.Location()), begin, end.In(begin.Location()), nil)...)
+	}
+
+	var (
+		active bool
+		next   time.Time
+	)
+	for _, interval := range intervals {
+		if !now.Before(interval[0]) && now.Before(interval[1]) {
+			active = true
+		}
+		for _, t := range interval {
+			if t.After(now) && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+	}
+	return active, next, nil
+}
+
+// dailyIntervals returns the occurrences of the daily window from start to end
+// (times of day in the location of now) starting between yesterday and a week
+// from now. If days is not empty, only occurrences starting on these weekdays
+// are returned. An end not after start ends on the following day.
+func dailyIntervals(now, start, end time.Time, days map[time.Weekday]bool) [][2]time.Time {
+	var intervals [][2]time.Time
+	for offset := -1; offset <= 7; offset++ {
+		from := time.Date(now.Year(), now.Month(), now.Day()+offset, start.Hour(), start.Minute(), start.Second(), 0, now.Location())
+		if len(days) > 0 && !days[from.Weekday()] {
+			continue
+		}
+		to := time.Date(now.Year(), now.Month(), now.Day()+offset, end.Hour(), end.Minute(), end.Second(), 0, now.Location())
+		if !to.After(from) {
+			to = to.AddDate(0, 0, 1)
+		}
+		intervals = append(intervals, [2]time.Time{from, to})
+	}
+	return intervals
+}
@@ -0,0 +1,132 @@
+package helper
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("schedule", func() {
+	var businessHours *acl.Schedule
+
+	BeforeEach(func() {
+		businessHours = &acl.Schedule{
+			Windows:  []acl.TimeWindow{{Days: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "08:00", End: "18:00"}},
+			Location: "Europe/Berlin",
+		}
+	})
+
+	Describe("#evaluateSchedule", func() {
+		It("should be active during a time window", func() {
+			// Wednesday, 10:00 in Berlin
+			active, next, err := evaluateSchedule(businessHours, utc("2026-10-14T08:00:00Z"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(active).To(BeTrue())
+			Expect(next).To(BeTemporally("==", utc("2026-10-14T16:00:00Z")))
+		})
+
+		It("should be inactive outside of the time windows", func() {
+			// Friday, 19:00 in Berlin
+			active, next, err := evaluateSchedule(businessHours, utc("2026-10-16T17:00:00Z"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(active).To(BeFalse())
+			Expect(next).To(BeTemporally("==", utc("2026-10-19T06:00:00Z")))
+		})
+
+		It("should respect daylight saving time", func() {
+			businessHours.Windows[0].Days = nil
+
+			// Saturday, 19:00 in Berlin, daylight saving time ends on Sunday
+			active, next, err := evaluateSchedule(businessHours, utc("2026-10-24T17:00:00Z"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(active).To(BeFalse())
+			Expect(next).To(BeTemporally("==", utc("2026-10-25T07:00:00Z")))
+		})
+
+		It("should handle time windows ending on the following day", func() {
+			schedule := &acl.Schedule{Windows: []acl.TimeWindow{{Start: "22:00", End: "06:00"}}, Location: "UTC"}
+
+			active, next, err := evaluateSchedule(schedule, utc("2026-10-14T03:00:00Z"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(active).To(BeTrue())
+			Expect(next).To(BeTemporally("==", utc("2026-10-14T06:00:00Z")))
+		})
+
+		It("should be active during the maintenance time window", func() {
+			schedule := &acl.Schedule{MaintenanceWindow: true, Location: "UTC"}
+			maintenance := &MaintenanceWindow{Begin: "220000+0200", End: "230000+0200"}
+
+			active, next, err := evaluateSchedule(schedule, utc("2026-10-14T20:30:00Z"), maintenance)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(active).To(BeTrue())
+			Expect(next).To(BeTemporally("==", utc("2026-10-14T21:00:00Z")))
+
+			_, _, err = evaluateSchedule(schedule, utc("2026-10-14T20:30:00Z"), nil)
+			Expect(err).To(MatchError("the shoot has no maintenance time window"))
+		})
+	})
+
+	Describe("#ApplySchedules", func() {
+		It("should remove inactive rules", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+					{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"11.0.0.0/8"}, Schedule: businessHours},
+				},
+			}
+
+			next, err := ApplySchedules(config, utc("2026-10-16T17:00:00Z"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(next).To(BeTemporally("==", utc("2026-10-19T06:00:00Z")))
+			Expect(config.Rules).To(Equal([]acl.ACLRule{
+				{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+			}))
+		})
+
+		It("should keep active rules", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"11.0.0.0/8"}, Schedule: businessHours},
+			}
+
+			_, err := ApplySchedules(config, utc("2026-10-14T08:00:00Z"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Rule).NotTo(BeNil())
+		})
+
+		It("should deny all addresses if no ALLOW rule is active", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"11.0.0.0/8"}, Schedule: businessHours},
+				APIServer: &acl.EndpointConfig{
+					Rules: []acl.ACLRule{{Action: "DENY", Type: "remote_ip", Cidrs: []string{"12.0.0.0/8"}, Schedule: businessHours}},
+				},
+			}
+
+			_, err := ApplySchedules(config, utc("2026-10-16T17:00:00Z"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Rule).To(BeNil())
+			Expect(config.Rules).To(Equal([]acl.ACLRule{
+				{Action: "DENY", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0", "::/0"}},
+			}))
+			Expect(config.APIServer.Rules).To(Equal([]acl.ACLRule{
+				{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0", "::/0"}},
+			}))
+		})
+
+		It("should return the zero time without schedules", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"11.0.0.0/8"}},
+			}
+
+			Expect(ApplySchedules(config, utc("2026-10-14T08:00:00Z"), nil)).To(BeZero())
+		})
+	})
+})
+
+func utc(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return t
+}
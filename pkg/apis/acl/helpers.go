@@ -0,0 +1,211 @@
+package acl
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// GetRules returns the ordered list of rules of the ACLConfig. If only the
+// single Rule field is set, it is returned as a list with one element.
+func (c *ACLConfig) GetRules() []ACLRule {
+	if c.Rule != nil {
+		return []ACLRule{*c.Rule}
+	}
+	return c.Rules
+}
+
+// GetAPIServerRules returns the rules for the API server endpoint.
+func (c *ACLConfig) GetAPIServerRules() []ACLRule {
+	return c.rulesFor(c.APIServer)
+}
+
+// GetVPNRules returns the rules for the VPN tunnel endpoint.
+func (c *ACLConfig) GetVPNRules() []ACLRule {
+	return c.rulesFor(c.VPN)
+}
+
+// GetHTTPProxyRules returns the rules for the unified HTTP proxy endpoint.
+func (c *ACLConfig) GetHTTPProxyRules() []ACLRule {
+	return c.rulesFor(c.HTTPProxy)
+}
+
+// GetIngressRules returns the rules for the observability ingress endpoint.
+func (c *ACLConfig) GetIngressRules() []ACLRule {
+	if c.Ingress == nil {
+		return c.GetRules()
+	}
+	return c.rulesFor(&c.Ingress.EndpointConfig)
+}
+
+// GetIngressComponentRules returns the rules of the ingress components by their
+// name. Components without rules are omitted.
+func (c *ACLConfig) GetIngressComponentRules() map[string][]ACLRule {
+	if c.Ingress == nil {
+		return nil
+	}
+
+	componentRules := map[string][]ACLRule{}
+	for name, component := range c.Ingress.Components {
+		if rules := component.GetRules(); len(rules) > 0 {
+			componentRules[name] = rules
+		}
+	}
+	return componentRules
+}
+
+// AllRules returns the rules of the ACLConfig including the rules of all
+// endpoint and component overrides.
+func (c *ACLConfig) AllRules() []ACLRule {
+	rules := slices.Clone(c.GetRules())
+	for _, endpoint := range c.Endpoints() {
+		rules = append(rules, endpoint.GetRules()...)
+	}
+	if c.Ingress != nil {
+		for _, component := range c.Ingress.Components {
+			rules = append(rules, component.GetRules()...)
+		}
+	}
+	return rules
+}
+
+// AllCIDRs returns the CIDRs of all rules of the ACLConfig, see AllRules. The
+// CIDRs are sorted, duplicates are kept.
+func (c *ACLConfig) AllCIDRs() []string {
+	var cidrs []string
+	for _, rule := range c.AllRules() {
+		cidrs = append(cidrs, rule.GetCIDRs()...)
+	}
+	slices.Sort(cidrs)
+	return cidrs
+}
+
+// UnexpiredCIDRs returns the CIDRs of all rules like AllCIDRs, but without the
+// CIDR entries expired at now. Expired entries are not rendered, so they do
+// not count towards the maximum number of CIDRs.
+func (c *ACLConfig) UnexpiredCIDRs(now time.Time) []string {
+	var cidrs []string
+	for _, rule := range c.AllRules() {
+		cidrs = append(cidrs, rule.Cidrs...)
+		for _, entry := range rule.CIDREntries {
+			if !entry.IsExpired(now) {
+				cidrs = append(cidrs, entry.CIDR)
+			}
+		}
+	}
+	slices.Sort(cidrs)
+	return cidrs
+}
+
+// AllCIDRSets returns the names of the CIDR sets referenced by all rules of the
+// ACLConfig, see AllRules. The names are sorted and unique.
+func (c *ACLConfig) AllCIDRSets() []string {
+	var names []string
+	for _, rule := range c.AllRules() {
+		names = append(names, rule.CIDRSets...)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// AllHosts returns the host names of all rules of the ACLConfig, see AllRules.
+// The host names are sorted and unique.
+func (c *ACLConfig) AllHosts() []string {
+	var hosts []string
+	for _, rule := range c.AllRules() {
+		hosts = append(hosts, rule.Hosts...)
+	}
+	slices.Sort(hosts)
+	return slices.Compact(hosts)
+}
+
+// AllCountries returns the country codes of all rules of the ACLConfig, see
+// AllRules. The country codes are sorted and unique.
+func (c *ACLConfig) AllCountries() []string {
+	var countries []string
+	for _, rule := range c.AllRules() {
+		countries = append(countries, rule.Countries...)
+	}
+	slices.Sort(countries)
+	return slices.Compact(countries)
+}
+
+// AllASNs returns the autonomous system numbers of all rules of the ACLConfig,
+// see AllRules. The numbers are sorted and unique.
+func (c *ACLConfig) AllASNs() []uint32 {
+	var asns []uint32
+	for _, rule := range c.AllRules() {
+		asns = append(asns, rule.ASNs...)
+	}
+	slices.Sort(asns)
+	return slices.Compact(asns)
+}
+
+// AllCIDRsFrom returns the references to resources containing CIDRs of all
+// rules of the ACLConfig, see AllRules. The references are unique and sorted by
+// resource name and key.
+func (c *ACLConfig) AllCIDRsFrom() []CIDRsFromReference {
+	var refs []CIDRsFromReference
+	for _, rule := range c.AllRules() {
+		if rule.CIDRsFrom != nil {
+			refs = append(refs, *rule.CIDRsFrom)
+		}
+	}
+	slices.SortFunc(refs, func(a, b CIDRsFromReference) int {
+		return cmp.Or(cmp.Compare(a.ResourceName, b.ResourceName), cmp.Compare(a.Key, b.Key))
+	})
+	return slices.Compact(refs)
+}
+
+// Endpoints returns the endpoint overrides of the ACLConfig by their field
+// name. Endpoints without an override are omitted. The components of the
+// ingress endpoint are not included.
+func (c *ACLConfig) Endpoints() map[string]*EndpointConfig {
+	endpoints := map[string]*EndpointConfig{}
+	for name, endpoint := range map[string]*EndpointConfig{
+		"apiServer": c.APIServer,
+		"vpn":       c.VPN,
+		"httpProxy": c.HTTPProxy,
+	} {
+		if endpoint != nil {
+			endpoints[name] = endpoint
+		}
+	}
+	if c.Ingress != nil {
+		endpoints["ingress"] = &c.Ingress.EndpointConfig
+	}
+	return endpoints
+}
+
+func (c *ACLConfig) rulesFor(endpoint *EndpointConfig) []ACLRule {
+	if endpoint != nil {
+		if rules := endpoint.GetRules(); len(rules) > 0 {
+			return rules
+		}
+	}
+	return c.GetRules()
+}
+
+// GetRules returns the ordered list of rules of the EndpointConfig. If only the
+// single Rule field is set, it is returned as a list with one element.
+func (e *EndpointConfig) GetRules() []ACLRule {
+	if e.Rule != nil {
+		return []ACLRule{*e.Rule}
+	}
+	return e.Rules
+}
+
+// IsExpired checks whether the CIDR entry has expired at now.
+func (e *CIDREntry) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && !e.ExpiresAt.After(now)
+}
+
+// GetCIDRs returns the CIDRs of the rule followed by the CIDRs of its CIDR
+// entries, regardless of whether they are expired.
+func (r *ACLRule) GetCIDRs() []string {
+	cidrs := slices.Clone(r.Cidrs)
+	for _, entry := range r.CIDREntries {
+		cidrs = append(cidrs, entry.CIDR)
+	}
+	return cidrs
+}
@@ -0,0 +1,155 @@
+package acl
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// IngressComponentPlutono is the name of the Plutono ingress component.
+	IngressComponentPlutono = "plutono"
+	// IngressComponentPrometheus is the name of the Prometheus ingress component.
+	IngressComponentPrometheus = "prometheus"
+	// IngressComponentAlertmanager is the name of the Alertmanager ingress component.
+	IngressComponentAlertmanager = "alertmanager"
+	// IngressComponentVali is the name of the Vali ingress component.
+	IngressComponentVali = "vali"
+)
+
+const (
+	// ModeEnforce enforces the rules of an ACLConfig. It is the default mode.
+	ModeEnforce = "Enforce"
+	// ModeShadow only records which connections the rules of an ACLConfig
+	// would allow or deny in the Envoy statistics, without enforcing them.
+	ModeShadow = "Shadow"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ACLConfig is the content of the ProviderConfig of the acl extension object.
+type ACLConfig struct {
+	metav1.TypeMeta
+
+	// Rule contain the user-defined Access Control Rule. It is a shorthand for
+	// a Rules list with exactly one element.
+	Rule *ACLRule
+	// Rules contains an ordered list of user-defined Access Control Rules. The
+	// rules are evaluated in order, the first rule matching the address of a
+	// connection decides whether it is allowed or denied.
+	Rules []ACLRule
+
+	// APIServer optionally overrides the rules for the API server endpoint.
+	APIServer *EndpointConfig
+	// VPN optionally overrides the rules for the VPN tunnel endpoint.
+	VPN *EndpointConfig
+	// HTTPProxy optionally overrides the rules for the unified HTTP proxy endpoint.
+	HTTPProxy *EndpointConfig
+	// Ingress optionally overrides the rules for the observability components
+	// exposed via the seed ingress domain.
+	Ingress *IngressConfig
+
+	// Mode is either Enforce (the default) or Shadow. In Shadow mode the rules
+	// are not enforced, but only evaluated as Envoy RBAC shadow rules.
+	Mode string
+}
+
+// EndpointConfig contains the rules for a single endpoint of the shoot.
+// Endpoints without rules fall back to the rules of the ACLConfig.
+type EndpointConfig struct {
+	// Rule contain the user-defined Access Control Rule. It is a shorthand for
+	// a Rules list with exactly one element.
+	Rule *ACLRule
+	// Rules contains an ordered list of user-defined Access Control Rules.
+	Rules []ACLRule
+}
+
+// IngressConfig contains the rules for the observability components exposed
+// via the seed ingress domain.
+type IngressConfig struct {
+	EndpointConfig
+
+	// Components optionally overrides the rules for single components by their
+	// name. All other components use the rules of the IngressConfig.
+	Components map[string]EndpointConfig
+}
+
+// CIDRsFromReference references CIDRs stored in a Secret or ConfigMap, which
+// is listed in spec.resources of the shoot and therefore copied into the shoot
+// namespace of the seed by Gardener.
+type CIDRsFromReference struct {
+	// ResourceName is the name of the resource reference in spec.resources of
+	// the shoot. The referenced resource must be a Secret or a ConfigMap.
+	ResourceName string
+	// Key is the key of the resource containing the CIDRs, separated by commas
+	// or whitespace.
+	Key string
+}
+
+// CIDREntry is a CIDR block with metadata and an optional expiry.
+type CIDREntry struct {
+	// CIDR is the CIDR block.
+	CIDR string
+	// Description describes why the CIDR block is allowed or denied.
+	Description string
+	// Owner is the person or team responsible for the entry.
+	Owner string
+	// ExpiresAt is the time the entry expires at. Expired entries are ignored.
+	ExpiresAt *metav1.Time
+}
+
+// Schedule defines when a rule is active: during any of its time windows or
+// during the maintenance time window of the shoot.
+type Schedule struct {
+	// Windows are the weekly time windows the rule is active in.
+	Windows []TimeWindow
+	// MaintenanceWindow activates the rule during the maintenance time window
+	// of the shoot.
+	MaintenanceWindow bool
+	// Location is the IANA time zone the windows are specified in, e.g.
+	// "Europe/Berlin".
+	Location string
+}
+
+// TimeWindow is a daily time window, optionally restricted to some weekdays.
+type TimeWindow struct {
+	// Days are the weekdays the window starts on, e.g. "Mon". All days if
+	// empty.
+	Days []string
+	// Start is the time of day the window starts at, e.g. "08:00".
+	Start string
+	// End is the time of day the window ends at, e.g. "18:00". If it is not
+	// after Start, the window ends on the following day.
+	End string
+}
+
+// ACLRule contains a single ACL rule, consisting of a list of CIDRs, an action
+// and a rule type.
+type ACLRule struct {
+	// Cidrs contains a list of CIDR blocks to which the ACL rule applies
+	Cidrs []string
+	// CIDREntries contains CIDR blocks with metadata and an optional expiry,
+	// which are added to Cidrs by the controller until they expire.
+	CIDREntries []CIDREntry
+	// CIDRSets contains the names of operator-defined CIDR sets, whose CIDRs
+	// are added to Cidrs by the controller.
+	CIDRSets []string
+	// CIDRsFrom references a Secret or ConfigMap listed in the resources of the
+	// shoot, whose CIDRs are added to Cidrs by the controller.
+	CIDRsFrom *CIDRsFromReference
+	// Hosts contains a list of host names, whose addresses are periodically
+	// resolved by the controller and added to Cidrs.
+	Hosts []string
+	// Countries contains a list of ISO 3166-1 alpha-2 country codes, whose
+	// CIDRs are looked up in the GeoIP database of the controller and added to
+	// Cidrs.
+	Countries []string
+	// ASNs contains a list of autonomous system numbers, whose CIDRs are looked
+	// up in the GeoIP database of the controller and added to Cidrs.
+	ASNs []uint32
+	// Schedule restricts the rule to time windows. A rule without a schedule
+	// is always active.
+	Schedule *Schedule
+	// Action defines if the rule is a DENY or an ALLOW rule
+	Action string
+	// Type can either be "source_ip", "direct_remote_ip" or "remote_ip"
+	Type string
+}
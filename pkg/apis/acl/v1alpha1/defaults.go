@@ -0,0 +1,22 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func addDefaultingFuncs(scheme *runtime.Scheme) error {
+	return RegisterDefaults(scheme)
+}
+
+// SetDefaults_ACLRule sets default values for ACLRule objects.
+func SetDefaults_ACLRule(obj *ACLRule) {
+	if obj.Type == "" {
+		obj.Type = "remote_ip"
+	}
+	if obj.CIDRsFrom != nil && obj.CIDRsFrom.Key == "" {
+		obj.CIDRsFrom.Key = "cidrs"
+	}
+	if obj.Schedule != nil && obj.Schedule.Location == "" {
+		obj.Schedule.Location = "UTC"
+	}
+}
@@ -0,0 +1,8 @@
+// +k8s:deepcopy-gen=package
+// +k8s:conversion-gen=github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl
+// +k8s:defaulter-gen=TypeMeta
+// +groupName=acl.extensions.gardener.cloud
+
+// Package v1alpha1 contains the v1alpha1 version of the ACL extension
+// configuration API.
+package v1alpha1
@@ -0,0 +1,166 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ACLConfig is the content of the ProviderConfig of the acl extension object.
+type ACLConfig struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Rule contain the user-defined Access Control Rule. It is a shorthand for
+	// a Rules list with exactly one element.
+	// +optional
+	Rule *ACLRule `json:"rule,omitempty"`
+	// Rules contains an ordered list of user-defined Access Control Rules. The
+	// rules are evaluated in order, the first rule matching the address of a
+	// connection decides whether it is allowed or denied.
+	// +optional
+	Rules []ACLRule `json:"rules,omitempty"`
+
+	// APIServer optionally overrides the rules for the API server endpoint.
+	// +optional
+	APIServer *EndpointConfig `json:"apiServer,omitempty"`
+	// VPN optionally overrides the rules for the VPN tunnel endpoint.
+	// +optional
+	VPN *EndpointConfig `json:"vpn,omitempty"`
+	// HTTPProxy optionally overrides the rules for the unified HTTP proxy endpoint.
+	// +optional
+	HTTPProxy *EndpointConfig `json:"httpProxy,omitempty"`
+	// Ingress optionally overrides the rules for the observability components
+	// exposed via the seed ingress domain.
+	// +optional
+	Ingress *IngressConfig `json:"ingress,omitempty"`
+
+	// Mode is either Enforce (the default) or Shadow. In Shadow mode the rules
+	// are not enforced, but only evaluated as Envoy RBAC shadow rules.
+	// +optional
+	Mode string `json:"mode,omitempty"`
+}
+
+// EndpointConfig contains the rules for a single endpoint of the shoot.
+// Endpoints without rules fall back to the rules of the ACLConfig.
+type EndpointConfig struct {
+	// Rule contain the user-defined Access Control Rule. It is a shorthand for
+	// a Rules list with exactly one element.
+	// +optional
+	Rule *ACLRule `json:"rule,omitempty"`
+	// Rules contains an ordered list of user-defined Access Control Rules.
+	// +optional
+	Rules []ACLRule `json:"rules,omitempty"`
+}
+
+// IngressConfig contains the rules for the observability components exposed
+// via the seed ingress domain.
+type IngressConfig struct {
+	EndpointConfig `json:",inline"`
+
+	// Components optionally overrides the rules for single components by their
+	// name, i.e. "plutono", "prometheus", "alertmanager" or "vali". All other
+	// components use the rules of the IngressConfig.
+	// +optional
+	Components map[string]EndpointConfig `json:"components,omitempty"`
+}
+
+// CIDRsFromReference references CIDRs stored in a Secret or ConfigMap, which
+// is listed in spec.resources of the shoot and therefore copied into the shoot
+// namespace of the seed by Gardener.
+type CIDRsFromReference struct {
+	// ResourceName is the name of the resource reference in spec.resources of
+	// the shoot. The referenced resource must be a Secret or a ConfigMap.
+	ResourceName string `json:"resourceName"`
+	// Key is the key of the resource containing the CIDRs, separated by commas
+	// or whitespace. Defaults to "cidrs".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// CIDREntry is a CIDR block with metadata and an optional expiry.
+type CIDREntry struct {
+	// CIDR is the CIDR block.
+	CIDR string `json:"cidr"`
+	// Description describes why the CIDR block is allowed or denied.
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Owner is the person or team responsible for the entry.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+	// ExpiresAt is the time the entry expires at. Expired entries are ignored.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+}
+
+// Schedule defines when a rule is active: during any of its time windows or
+// during the maintenance time window of the shoot.
+type Schedule struct {
+	// Windows are the weekly time windows the rule is active in.
+	// +optional
+	Windows []TimeWindow `json:"windows,omitempty"`
+	// MaintenanceWindow activates the rule during the maintenance time window
+	// of the shoot.
+	// +optional
+	MaintenanceWindow bool `json:"maintenanceWindow,omitempty"`
+	// Location is the IANA time zone the windows are specified in, e.g.
+	// "Europe/Berlin". Defaults to "UTC".
+	// +optional
+	Location string `json:"location,omitempty"`
+}
+
+// TimeWindow is a daily time window, optionally restricted to some weekdays.
+type TimeWindow struct {
+	// Days are the weekdays the window starts on, e.g. "Mon". All days if
+	// empty.
+	// +optional
+	Days []string `json:"days,omitempty"`
+	// Start is the time of day the window starts at, e.g. "08:00".
+	Start string `json:"start"`
+	// End is the time of day the window ends at, e.g. "18:00". If it is not
+	// after Start, the window ends on the following day.
+	End string `json:"end"`
+}
+
+// ACLRule contains a single ACL rule, consisting of a list of CIDRs, an action
+// and a rule type.
+type ACLRule struct {
+	// Cidrs contains a list of CIDR blocks to which the ACL rule applies. It
+	// may be empty if the rule gets its CIDRs from any of the other sources.
+	// +optional
+	Cidrs []string `json:"cidrs,omitempty"`
+	// CIDREntries contains CIDR blocks with metadata and an optional expiry,
+	// which are added to Cidrs by the controller until they expire.
+	// +optional
+	CIDREntries []CIDREntry `json:"cidrEntries,omitempty"`
+	// CIDRSets contains the names of operator-defined CIDR sets, whose CIDRs
+	// are added to Cidrs by the controller.
+	// +optional
+	CIDRSets []string `json:"cidrSets,omitempty"`
+	// CIDRsFrom references a Secret or ConfigMap listed in the resources of the
+	// shoot, whose CIDRs are added to Cidrs by the controller.
+	// +optional
+	CIDRsFrom *CIDRsFromReference `json:"cidrsFrom,omitempty"`
+	// Hosts contains a list of host names, whose addresses are periodically
+	// resolved by the controller and added to Cidrs.
+	// +optional
+	Hosts []string `json:"hosts,omitempty"`
+	// Countries contains a list of ISO 3166-1 alpha-2 country codes, whose
+	// CIDRs are looked up in the GeoIP database of the controller and added to
+	// Cidrs.
+	// +optional
+	Countries []string `json:"countries,omitempty"`
+	// ASNs contains a list of autonomous system numbers, whose CIDRs are looked
+	// up in the GeoIP database of the controller and added to Cidrs.
+	// +optional
+	ASNs []uint32 `json:"asns,omitempty"`
+	// Schedule restricts the rule to time windows. A rule without a schedule
+	// is always active.
+	// +optional
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// Action defines if the rule is a DENY or an ALLOW rule
+	Action string `json:"action"`
+	// Type can either be "source_ip", "direct_remote_ip" or "remote_ip".
+	// Defaults to "remote_ip".
+	// +optional
+	Type string `json:"type,omitempty"`
+}
@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+// Policy is an operator-defined policy for the CIDRs of ALLOW rules, which is
+// enforced in addition to the validation of the ACLConfig. DENY rules can only
+// restrict the access further, so they are not subject to the policy.
+type Policy struct {
+	// MinPrefixLengthIPv4 is the minimum prefix length of IPv4 CIDRs, zero
+	// disables the check. Allowing all addresses (0.0.0.0/0) is controlled by
+	// ForbidAllAddresses.
+	MinPrefixLengthIPv4 int
+	// MinPrefixLengthIPv6 is the minimum prefix length of IPv6 CIDRs, zero
+	// disables the check. Allowing all addresses (::/0) is controlled by
+	// ForbidAllAddresses.
+	MinPrefixLengthIPv6 int
+	// ForbidAllAddresses forbids allowing all addresses with 0.0.0.0/0 or ::/0.
+	ForbidAllAddresses bool
+	// ForbiddenCIDRs are ranges which must not be allowed, e.g. shared NAT
+	// gateways. CIDRs within these ranges are forbidden, broader CIDRs are
+	// subject to the minimum prefix lengths.
+	ForbiddenCIDRs []string
+}
+
+// IsEmpty returns true if the policy does not restrict any CIDRs.
+func (p *Policy) IsEmpty() bool {
+	return p.MinPrefixLengthIPv4 == 0 && p.MinPrefixLengthIPv6 == 0 && !p.ForbidAllAddresses && len(p.ForbiddenCIDRs) == 0
+}
+
+// ValidatePolicy validates the policy itself.
+func ValidatePolicy(policy *Policy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if policy.MinPrefixLengthIPv4 < 0 || policy.MinPrefixLengthIPv4 > 32 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minPrefixLengthIPv4"), policy.MinPrefixLengthIPv4, "must be between 0 and 32"))
+	}
+	if policy.MinPrefixLengthIPv6 < 0 || policy.MinPrefixLengthIPv6 > 128 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("minPrefixLengthIPv6"), policy.MinPrefixLengthIPv6, "must be between 0 and 128"))
+	}
+	for i, cidr := range policy.ForbiddenCIDRs {
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("forbiddenCIDRs").Index(i), cidr, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateACLConfigPolicy checks the CIDRs of all ALLOW rules of the given
+// ACLConfig against the policy and returns all violations. ALLOW rules must not
+// use CIDR sets, cidrsFrom references, hosts, countries or ASNs, as their CIDRs
+// are only resolved by the controller and may change at any time, so they
+// cannot be checked against the policy. The config is expected to be valid,
+// invalid CIDRs are skipped.
+func ValidateACLConfigPolicy(config *acl.ACLConfig, policy *Policy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if policy == nil || policy.IsEmpty() {
+		return allErrs
+	}
+
+	var forbidden []netip.Prefix
+	for _, cidr := range policy.ForbiddenCIDRs {
+		if prefix, err := aclhelper.ParseCanonicalPrefix(cidr); err == nil {
+			forbidden = append(forbidden, prefix)
+		}
+	}
+	minPrefixLengths := map[int]int{
+		32:  policy.MinPrefixLengthIPv4,
+		128: policy.MinPrefixLengthIPv6,
+	}
+
+	forEachRule(config, fldPath, func(rule *acl.ACLRule, rulePath *field.Path) {
+		if !strings.EqualFold(rule.Action, "ALLOW") {
+			return
+		}
+		allErrs = append(allErrs, validateUncheckableSources(rule, rulePath)...)
+
+		for _, c := range ruleCIDRs(rule, rulePath) {
+			cidr, cidrPath := c.cidr, c.path
+			prefix, err := aclhelper.ParseCanonicalPrefix(cidr)
+			if err != nil {
+				continue
+			}
+
+			if prefix.Bits() == 0 {
+				if policy.ForbidAllAddresses {
+					allErrs = append(allErrs, field.Forbidden(cidrPath, "allowing all addresses is forbidden by the policy"))
+				}
+			} else if minPrefixLength := minPrefixLengths[prefix.Addr().BitLen()]; prefix.Bits() < minPrefixLength {
+				allErrs = append(allErrs, field.Invalid(cidrPath, cidr, fmt.Sprintf("the policy requires a prefix length of at least %d", minPrefixLength)))
+			}
+
+			if f, ok := coveringPrefix(forbidden, prefix); ok {
+				allErrs = append(allErrs, field.Forbidden(cidrPath, fmt.Sprintf("%s is part of the range %s, which must not be allowed by the policy", cidr, f)))
+			}
+		}
+	})
+
+	return allErrs
+}
+
+// validateUncheckableSources forbids the sources of the given rule whose CIDRs
+// cannot be checked against the policy by the admission webhook.
+func validateUncheckableSources(rule *acl.ACLRule, rulePath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	const detail = "the CIDRs are only resolved by the controller and cannot be checked against the policy"
+	if len(rule.CIDRSets) > 0 {
+		allErrs = append(allErrs, field.Forbidden(rulePath.Child("cidrSets"), detail))
+	}
+	if rule.CIDRsFrom != nil {
+		allErrs = append(allErrs, field.Forbidden(rulePath.Child("cidrsFrom"), detail))
+	}
+	if len(rule.Hosts) > 0 {
+		allErrs = append(allErrs, field.Forbidden(rulePath.Child("hosts"), detail))
+	}
+	if len(rule.Countries) > 0 {
+		allErrs = append(allErrs, field.Forbidden(rulePath.Child("countries"), detail))
+	}
+	if len(rule.ASNs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(rulePath.Child("asns"), detail))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,155 @@
+package validation
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("Policy", func() {
+	var fldPath *field.Path
+
+	BeforeEach(func() {
+		fldPath = field.NewPath("providerConfig")
+	})
+
+	Describe("#ValidatePolicy", func() {
+		It("should allow an empty policy", func() {
+			Expect(ValidatePolicy(&Policy{}, field.NewPath("policy"))).To(BeEmpty())
+		})
+
+		It("should forbid invalid prefix lengths and CIDRs", func() {
+			policy := &Policy{
+				MinPrefixLengthIPv4: 33,
+				MinPrefixLengthIPv6: -1,
+				ForbiddenCIDRs:      []string{"192.0.2.0/28", "foo"},
+			}
+
+			Expect(ValidatePolicy(policy, field.NewPath("policy"))).To(ConsistOf(
+				matchError(field.ErrorTypeInvalid, "policy.minPrefixLengthIPv4"),
+				matchError(field.ErrorTypeInvalid, "policy.minPrefixLengthIPv6"),
+				matchError(field.ErrorTypeInvalid, "policy.forbiddenCIDRs[1]"),
+			))
+		})
+	})
+
+	Describe("#ValidateACLConfigPolicy", func() {
+		var policy *Policy
+
+		BeforeEach(func() {
+			policy = &Policy{
+				MinPrefixLengthIPv4: 16,
+				MinPrefixLengthIPv6: 48,
+				ForbidAllAddresses:  true,
+				ForbiddenCIDRs:      []string{"192.0.2.0/28"},
+			}
+		})
+
+		It("should allow CIDRs complying with the policy", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/16", "2001:db8::/48", "192.0.2.16/28"}},
+			}
+
+			Expect(ValidateACLConfigPolicy(config, policy, fldPath)).To(BeEmpty())
+		})
+
+		It("should not apply the policy to DENY rules", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{Action: "DENY", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0", "10.0.0.0/8", "192.0.2.1/32"}},
+				},
+			}
+
+			Expect(ValidateACLConfigPolicy(config, policy, fldPath)).To(BeEmpty())
+		})
+
+		It("should forbid allowing all addresses", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0", "::/0"}},
+			}
+
+			Expect(ValidateACLConfigPolicy(config, policy, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeForbidden, "providerConfig.rule.cidrs[0]"),
+				matchError(field.ErrorTypeForbidden, "providerConfig.rule.cidrs[1]"),
+			))
+
+			policy.ForbidAllAddresses = false
+			Expect(ValidateACLConfigPolicy(config, policy, fldPath)).To(BeEmpty())
+		})
+
+		It("should forbid CIDRs shorter than the minimum prefix length", func() {
+			config := &acl.ACLConfig{
+				APIServer: &acl.EndpointConfig{
+					Rules: []acl.ACLRule{{Action: "allow", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "2001:db8::/32"}}},
+				},
+			}
+
+			Expect(ValidateACLConfigPolicy(config, policy, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeInvalid, "providerConfig.apiServer.rules[0].cidrs[0]"),
+				matchError(field.ErrorTypeInvalid, "providerConfig.apiServer.rules[0].cidrs[1]"),
+			))
+		})
+
+		It("should apply the policy to CIDR entries", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/16"}, CIDREntries: []acl.CIDREntry{
+					{CIDR: "10.1.0.0/16"},
+					{CIDR: "10.0.0.0/8"},
+				}},
+			}
+
+			Expect(ValidateACLConfigPolicy(config, policy, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeInvalid, "providerConfig.rule.cidrEntries[1].cidr"),
+			))
+		})
+
+		It("should forbid CIDRs within the forbidden ranges", func() {
+			config := &acl.ACLConfig{
+				Ingress: &acl.IngressConfig{
+					Components: map[string]acl.EndpointConfig{
+						acl.IngressComponentPlutono: {Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"192.0.2.1/32"}}},
+					},
+				},
+			}
+
+			Expect(ValidateACLConfigPolicy(config, policy, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeForbidden, "providerConfig.ingress.components[plutono].rule.cidrs[0]"),
+			))
+		})
+
+		It("should forbid sources resolved by the controller in ALLOW rules", func() {
+			config := &acl.ACLConfig{
+				Rules: []acl.ACLRule{
+					{
+						Action:    "ALLOW",
+						Type:      "remote_ip",
+						CIDRSets:  []string{"corporate-vpn"},
+						CIDRsFrom: &acl.CIDRsFromReference{ResourceName: "acl-cidrs", Key: "cidrs"},
+						Hosts:     []string{"example.com"},
+						Countries: []string{"DE"},
+						ASNs:      []uint32{64496},
+					},
+					{Action: "DENY", Type: "remote_ip", Hosts: []string{"example.org"}, Countries: []string{"FR"}},
+				},
+			}
+
+			Expect(ValidateACLConfigPolicy(config, policy, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeForbidden, "providerConfig.rules[0].cidrSets"),
+				matchError(field.ErrorTypeForbidden, "providerConfig.rules[0].cidrsFrom"),
+				matchError(field.ErrorTypeForbidden, "providerConfig.rules[0].hosts"),
+				matchError(field.ErrorTypeForbidden, "providerConfig.rules[0].countries"),
+				matchError(field.ErrorTypeForbidden, "providerConfig.rules[0].asns"),
+			))
+		})
+
+		It("should not return errors for an empty policy", func() {
+			config := &acl.ACLConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0"}},
+			}
+
+			Expect(ValidateACLConfigPolicy(config, &Policy{}, fldPath)).To(BeEmpty())
+		})
+	})
+})
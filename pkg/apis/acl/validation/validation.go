@@ -0,0 +1,389 @@
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+var (
+	supportedActions           = sets.New("ALLOW", "DENY")
+	supportedModes             = sets.New(acl.ModeEnforce, acl.ModeShadow)
+	supportedTypes             = sets.New("direct_remote_ip", "remote_ip", "source_ip")
+	supportedIngressComponents = sets.New(
+		acl.IngressComponentPlutono,
+		acl.IngressComponentPrometheus,
+		acl.IngressComponentAlertmanager,
+		acl.IngressComponentVali,
+	)
+)
+
+// ValidateACLConfig validates the given ACLConfig and returns all errors found.
+// Every endpoint must either have its own rules or fall back to the default
+// rules of the ACLConfig. If maxAllowedCIDRs is greater than zero, the total
+// number of CIDRs, without the CIDR entries expired by now, must not exceed it.
+func ValidateACLConfig(config *acl.ACLConfig, maxAllowedCIDRs int, fldPath *field.Path) field.ErrorList {
+	return ValidateACLConfigUpdate(config, nil, maxAllowedCIDRs, time.Now(), fldPath)
+}
+
+// ValidateACLConfigUpdate validates the given ACLConfig like ValidateACLConfig,
+// but grandfathers configs exceeding maxAllowedCIDRs (e.g. because the limit
+// was lowered): as long as an update neither increases the number of CIDRs nor
+// adds CIDRs not contained in oldCIDRs, it is accepted. oldCIDRs are the CIDRs
+// of the previous config, see acl.ACLConfig.UnexpiredCIDRs. CIDR entries expired
+// at now are not counted.
+func ValidateACLConfigUpdate(
+	config *acl.ACLConfig, oldCIDRs []string, maxAllowedCIDRs int, now time.Time, fldPath *field.Path,
+) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, validateEndpointConfig(config.Rule, config.Rules, fldPath)...)
+
+	endpoints := config.Endpoints()
+	for _, name := range []string{"apiServer", "vpn", "httpProxy", "ingress"} {
+		endpoint, ok := endpoints[name]
+		if !ok {
+			continue
+		}
+		allErrs = append(allErrs, validateEndpointConfig(endpoint.Rule, endpoint.Rules, fldPath.Child(name))...)
+	}
+
+	if config.Ingress != nil {
+		componentsPath := fldPath.Child("ingress", "components")
+		for _, name := range slices.Sorted(maps.Keys(config.Ingress.Components)) {
+			component := config.Ingress.Components[name]
+			if !supportedIngressComponents.Has(name) {
+				allErrs = append(allErrs, field.NotSupported(componentsPath, name, sets.List(supportedIngressComponents)))
+				continue
+			}
+			if len(component.GetRules()) == 0 {
+				allErrs = append(allErrs, field.Required(componentsPath.Key(name).Child("rule"), "rule must be present"))
+				continue
+			}
+			allErrs = append(allErrs, validateEndpointConfig(component.Rule, component.Rules, componentsPath.Key(name))...)
+		}
+	}
+
+	if config.Mode != "" && !supportedModes.Has(config.Mode) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("mode"), config.Mode, sets.List(supportedModes)))
+	}
+
+	if len(config.GetRules()) == 0 {
+		allErrs = append(allErrs, validateEndpointsHaveRules(config, fldPath)...)
+	}
+
+	if cidrs := config.UnexpiredCIDRs(now); maxAllowedCIDRs > 0 && len(cidrs) > maxAllowedCIDRs && !isGrandfathered(cidrs, oldCIDRs) {
+		allErrs = append(allErrs, field.TooMany(cidrsPath(config, fldPath), len(cidrs), maxAllowedCIDRs))
+	}
+
+	return allErrs
+}
+
+// ValidateRenderedCIDRs checks that the rules of every endpoint and ingress
+// component of the given ACLConfig contain at most maxRenderedCIDRs CIDRs once
+// their CIDR sets, hosts, countries, ASNs and CIDR entries are resolved. As the
+// CIDRs are rendered as principals of the RBAC filters, this limits the size of
+// the filters independently of the limit of the CIDRs of the providerConfig. A
+// maxRenderedCIDRs of zero means no limit.
+func ValidateRenderedCIDRs(config *acl.ACLConfig, maxRenderedCIDRs int, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if maxRenderedCIDRs <= 0 {
+		return allErrs
+	}
+
+	endpoints := map[string][]acl.ACLRule{
+		"apiServer": config.GetAPIServerRules(),
+		"vpn":       config.GetVPNRules(),
+		"httpProxy": config.GetHTTPProxyRules(),
+		"ingress":   config.GetIngressRules(),
+	}
+	for name, rules := range config.GetIngressComponentRules() {
+		endpoints["ingress.components."+name] = rules
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(endpoints)) {
+		count := 0
+		for _, rule := range endpoints[name] {
+			count += len(rule.GetCIDRs())
+		}
+		if count > maxRenderedCIDRs {
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf(
+				"the rules of the %s endpoint resolve to %d CIDRs, at most %d are allowed", name, count, maxRenderedCIDRs,
+			)))
+		}
+	}
+	return allErrs
+}
+
+// isGrandfathered checks that the given CIDRs neither outnumber the oldCIDRs nor
+// contain CIDRs not contained in them.
+func isGrandfathered(cidrs, oldCIDRs []string) bool {
+	if len(oldCIDRs) == 0 || len(cidrs) > len(oldCIDRs) {
+		return false
+	}
+
+	old := sets.New[string]()
+	for _, cidr := range oldCIDRs {
+		old.Insert(canonicalCIDR(cidr))
+	}
+	for _, cidr := range cidrs {
+		if !old.Has(canonicalCIDR(cidr)) {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalCIDR returns the canonical form of the given CIDR, so CIDRs differing
+// only in their notation are considered equal. Invalid CIDRs are returned as is.
+func canonicalCIDR(cidr string) string {
+	prefix, err := aclhelper.ParseCanonicalPrefix(cidr)
+	if err != nil {
+		return cidr
+	}
+	return prefix.String()
+}
+
+// validateEndpointsHaveRules checks that every endpoint has its own rules if
+// the ACLConfig has no default rules.
+func validateEndpointsHaveRules(config *acl.ACLConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	endpoints := config.Endpoints()
+	if len(endpoints) == 0 {
+		return append(allErrs, field.Required(fldPath.Child("rule"), "rule must be present"))
+	}
+
+	for _, name := range []string{"apiServer", "vpn", "httpProxy", "ingress"} {
+		if endpoint, ok := endpoints[name]; !ok || len(endpoint.GetRules()) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child(name), "rules must be present for every endpoint if no default rule is given"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateEndpointConfig(rule *acl.ACLRule, rules []acl.ACLRule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if rule != nil && len(rules) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("rules"), "rule and rules must not be set at the same time"))
+	}
+
+	if rule != nil {
+		allErrs = append(allErrs, validateRule(rule, fldPath.Child("rule"))...)
+	}
+	for i := range rules {
+		allErrs = append(allErrs, validateRule(&rules[i], fldPath.Child("rules").Index(i))...)
+	}
+
+	return allErrs
+}
+
+func validateRule(rule *acl.ACLRule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !supportedActions.Has(strings.ToUpper(rule.Action)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("action"), rule.Action, sets.List(supportedActions)))
+	}
+
+	if !supportedTypes.Has(strings.ToLower(rule.Type)) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), rule.Type, sets.List(supportedTypes)))
+	}
+
+	if len(rule.Cidrs) == 0 && len(rule.CIDREntries) == 0 && len(rule.CIDRSets) == 0 && rule.CIDRsFrom == nil &&
+		len(rule.Hosts) == 0 && len(rule.Countries) == 0 && len(rule.ASNs) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("cidrs"), "CIDRs must not be empty"))
+	}
+	for i, cidr := range rule.Cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cidrs").Index(i), cidr, err.Error()))
+		}
+	}
+	for i, entry := range rule.CIDREntries {
+		if _, _, err := net.ParseCIDR(entry.CIDR); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cidrEntries").Index(i).Child("cidr"), entry.CIDR, err.Error()))
+		}
+	}
+	for i, name := range rule.CIDRSets {
+		if msgs := utilvalidation.IsConfigMapKey(name); len(msgs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cidrSets").Index(i), name, strings.Join(msgs, "; ")))
+		}
+	}
+	if rule.CIDRsFrom != nil {
+		allErrs = append(allErrs, validateCIDRsFrom(rule.CIDRsFrom, fldPath.Child("cidrsFrom"))...)
+	}
+	for i, host := range rule.Hosts {
+		if msgs := utilvalidation.IsDNS1123Subdomain(strings.ToLower(host)); len(msgs) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hosts").Index(i), host, strings.Join(msgs, "; ")))
+		}
+	}
+	for i, country := range rule.Countries {
+		if !countryCodes.Has(strings.ToUpper(country)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("countries").Index(i), country, "must be an ISO 3166-1 alpha-2 country code"))
+		}
+	}
+	for i, asn := range rule.ASNs {
+		if reservedASNs.Has(asn) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("asns").Index(i), asn, "must not be a reserved autonomous system number"))
+		}
+	}
+	if rule.Schedule != nil {
+		allErrs = append(allErrs, validateSchedule(rule.Schedule, fldPath.Child("schedule"))...)
+	}
+
+	return allErrs
+}
+
+func validateCIDRsFrom(ref *acl.CIDRsFromReference, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if ref.ResourceName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("resourceName"), "the name of a resource in spec.resources of the shoot is required"))
+	}
+	if msgs := utilvalidation.IsConfigMapKey(ref.Key); len(msgs) > 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("key"), ref.Key, strings.Join(msgs, "; ")))
+	}
+
+	return allErrs
+}
+
+func validateSchedule(schedule *acl.Schedule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(schedule.Windows) == 0 && !schedule.MaintenanceWindow {
+		allErrs = append(allErrs, field.Required(fldPath.Child("windows"), "time windows are required if the maintenance time window is not used"))
+	}
+	if _, err := time.LoadLocation(schedule.Location); err != nil || schedule.Location == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("location"), schedule.Location, "must be an IANA time zone, e.g. Europe/Berlin"))
+	}
+	for i, window := range schedule.Windows {
+		windowPath := fldPath.Child("windows").Index(i)
+		for j, day := range window.Days {
+			if _, err := aclhelper.ParseWeekday(day); err != nil {
+				allErrs = append(allErrs, field.Invalid(windowPath.Child("days").Index(j), day, err.Error()))
+			}
+		}
+		start, startErr := aclhelper.ParseTimeOfDay(window.Start)
+		if startErr != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("start"), window.Start, startErr.Error()))
+		}
+		end, endErr := aclhelper.ParseTimeOfDay(window.End)
+		if endErr != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("end"), window.End, endErr.Error()))
+		}
+		if startErr == nil && endErr == nil && start.Equal(end) {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("end"), window.End, "must differ from start"))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateCIDREntryExpiry checks that no CIDR entry of the given ACLConfig has
+// already expired at now. Expired entries of oldConfig which are kept unchanged
+// are accepted, so an update of an unrelated field is not rejected just because
+// an entry expired in the meantime. oldConfig may be nil.
+func ValidateCIDREntryExpiry(config, oldConfig *acl.ACLConfig, now time.Time, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	oldExpired := sets.New[string]()
+	if oldConfig != nil {
+		for _, rule := range oldConfig.AllRules() {
+			for _, entry := range rule.CIDREntries {
+				if entry.IsExpired(now) {
+					oldExpired.Insert(expiredEntryKey(entry))
+				}
+			}
+		}
+	}
+
+	forEachRule(config, fldPath, func(rule *acl.ACLRule, rulePath *field.Path) {
+		for i, entry := range rule.CIDREntries {
+			if !entry.IsExpired(now) || oldExpired.Has(expiredEntryKey(entry)) {
+				continue
+			}
+			allErrs = append(allErrs, field.Invalid(rulePath.Child("cidrEntries").Index(i).Child("expiresAt"),
+				entry.ExpiresAt.UTC().Format(time.RFC3339), "must be in the future"))
+		}
+	})
+
+	return allErrs
+}
+
+func expiredEntryKey(entry acl.CIDREntry) string {
+	return entry.CIDR + "@" + entry.ExpiresAt.UTC().Format(time.RFC3339)
+}
+
+// cidrsPath returns the path the CIDRs of the ACLConfig are configured at. If
+// the config consists of a single rule only, it points to its CIDRs, otherwise
+// to the list of rules or, with endpoint overrides, to the whole config.
+func cidrsPath(config *acl.ACLConfig, fldPath *field.Path) *field.Path {
+	switch {
+	case len(config.Endpoints()) > 0:
+		return fldPath
+	case config.Rule != nil:
+		return fldPath.Child("rule", "cidrs")
+	default:
+		return fldPath.Child("rules")
+	}
+}
+
+// ruleCIDR is a CIDR of a rule together with its path.
+type ruleCIDR struct {
+	cidr string
+	path *field.Path
+}
+
+// ruleCIDRs returns the CIDRs of the given rule followed by the CIDRs of its
+// CIDR entries.
+func ruleCIDRs(rule *acl.ACLRule, rulePath *field.Path) []ruleCIDR {
+	cidrs := make([]ruleCIDR, 0, len(rule.Cidrs)+len(rule.CIDREntries))
+	for i, cidr := range rule.Cidrs {
+		cidrs = append(cidrs, ruleCIDR{cidr: cidr, path: rulePath.Child("cidrs").Index(i)})
+	}
+	for i, entry := range rule.CIDREntries {
+		cidrs = append(cidrs, ruleCIDR{cidr: entry.CIDR, path: rulePath.Child("cidrEntries").Index(i).Child("cidr")})
+	}
+	return cidrs
+}
+
+// forEachRule calls fn for every rule of the given ACLConfig with the path of the
+// rule. The rules are visited in a stable order.
+func forEachRule(config *acl.ACLConfig, fldPath *field.Path, fn func(rule *acl.ACLRule, rulePath *field.Path)) {
+	visit := func(rule *acl.ACLRule, rules []acl.ACLRule, endpointPath *field.Path) {
+		if rule != nil {
+			fn(rule, endpointPath.Child("rule"))
+		}
+		for i := range rules {
+			fn(&rules[i], endpointPath.Child("rules").Index(i))
+		}
+	}
+
+	visit(config.Rule, config.Rules, fldPath)
+
+	endpoints := config.Endpoints()
+	for _, name := range []string{"apiServer", "vpn", "httpProxy", "ingress"} {
+		if endpoint, ok := endpoints[name]; ok {
+			visit(endpoint.Rule, endpoint.Rules, fldPath.Child(name))
+		}
+	}
+
+	if config.Ingress != nil {
+		componentsPath := fldPath.Child("ingress", "components")
+		for _, name := range slices.Sorted(maps.Keys(config.Ingress.Components)) {
+			component := config.Ingress.Components[name]
+			visit(component.Rule, component.Rules, componentsPath.Key(name))
+		}
+	}
+}
@@ -0,0 +1,500 @@
+package validation
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	gomegatypes "github.com/onsi/gomega/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("ValidateACLConfig", func() {
+	const maxAllowedCIDRs = 5
+
+	var (
+		fldPath *field.Path
+		config  *acl.ACLConfig
+	)
+
+	BeforeEach(func() {
+		fldPath = field.NewPath("providerConfig")
+		config = &acl.ACLConfig{
+			Rule: &acl.ACLRule{Action: "DENY", Type: "source_ip", Cidrs: []string{"0.0.0.0/0"}},
+		}
+	})
+
+	It("should allow a valid rule", func() {
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should allow an ordered list of valid rules", func() {
+		config = &acl.ACLConfig{
+			Rules: []acl.ACLRule{
+				{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.1.2.3/32"}},
+				{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+			},
+		}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid rule and rules at the same time", func() {
+		config.Rules = []acl.ACLRule{
+			{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+		}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeForbidden, "providerConfig.rules"),
+		))
+	})
+
+	It("should require a rule", func() {
+		Expect(ValidateACLConfig(&acl.ACLConfig{}, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeRequired, "providerConfig.rule"),
+		))
+	})
+
+	It("should return all errors of a rule with exact paths", func() {
+		config.Rule = &acl.ACLRule{
+			Action: "NONEXISTENT",
+			Type:   "nonexistent",
+			Cidrs:  []string{"10.0.0.0/8", "n0n3x1st3/nt", "10.1.0.0/16", "10.2.0.0"},
+		}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeNotSupported, "providerConfig.rule.action"),
+			matchError(field.ErrorTypeNotSupported, "providerConfig.rule.type"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.cidrs[1]"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.cidrs[3]"),
+		))
+	})
+
+	It("should return the errors of all rules of a list", func() {
+		config = &acl.ACLConfig{
+			Rules: []acl.ACLRule{
+				{Action: "deny", Type: "REMOTE_IP", Cidrs: []string{"10.1.2.3"}},
+				{Action: "ALLOW", Type: "remote_ip"},
+			},
+		}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeInvalid, "providerConfig.rules[0].cidrs[0]"),
+			matchError(field.ErrorTypeRequired, "providerConfig.rules[1].cidrs"),
+		))
+	})
+
+	It("should allow rules referencing only CIDR sets", func() {
+		config.Rule = &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDRSets: []string{"corporate-vpn", "ci-runners"}}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid invalid CIDR set names", func() {
+		config.Rule.CIDRSets = []string{"corporate-vpn", "ci runners", ""}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.cidrSets[1]"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.cidrSets[2]"),
+		))
+	})
+
+	It("should allow rules referencing only a resource", func() {
+		config.Rule = &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDRsFrom: &acl.CIDRsFromReference{ResourceName: "acl-cidrs", Key: "cidrs"}}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid invalid resource references", func() {
+		config.Rule.CIDRsFrom = &acl.CIDRsFromReference{Key: "ci runners"}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeRequired, "providerConfig.rule.cidrsFrom.resourceName"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.cidrsFrom.key"),
+		))
+	})
+
+	It("should allow rules with only hosts", func() {
+		config.Rule = &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Hosts: []string{"office.example.com", "VPN.example.com"}}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid invalid hosts", func() {
+		config.Rule.Hosts = []string{"office.example.com", "office_example.com", "10.1.2.3/32"}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.hosts[1]"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.hosts[2]"),
+		))
+	})
+
+	It("should allow rules with only countries and ASNs", func() {
+		config.Rule = &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Countries: []string{"DE", "fr"}, ASNs: []uint32{64496}}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid invalid countries and reserved ASNs", func() {
+		config.Rule.Countries = []string{"DE", "EU", "DEU"}
+		config.Rule.ASNs = []uint32{64496, 0, 23456}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.countries[1]"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.countries[2]"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.asns[1]"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.asns[2]"),
+		))
+	})
+
+	It("should allow rules with only CIDR entries", func() {
+		config.Rule = &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", CIDREntries: []acl.CIDREntry{
+			{CIDR: "10.1.2.3/32", Owner: "team-a", Description: "office"},
+		}}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid invalid CIDR entries and count them", func() {
+		config.Rule.CIDREntries = []acl.CIDREntry{{CIDR: "10.1.2.3/32"}, {CIDR: "office"}}
+
+		Expect(ValidateACLConfig(config, 2, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.cidrEntries[1].cidr"),
+			matchError(field.ErrorTypeTooMany, "providerConfig.rule.cidrs"),
+		))
+	})
+
+	It("should not count expired CIDR entries", func() {
+		config.Rule.CIDREntries = []acl.CIDREntry{
+			{CIDR: "10.1.2.3/32", ExpiresAt: &metav1.Time{Time: time.Now().Add(-time.Hour)}},
+			{CIDR: "10.1.2.4/32", ExpiresAt: &metav1.Time{Time: time.Now().Add(time.Hour)}},
+		}
+
+		Expect(ValidateACLConfig(config, 2, fldPath)).To(BeEmpty())
+	})
+
+	It("should allow valid schedules", func() {
+		config.Rule.Schedule = &acl.Schedule{
+			Windows:           []acl.TimeWindow{{Days: []string{"Mon", "Fri"}, Start: "08:00", End: "18:00"}, {Start: "22:00", End: "02:00"}},
+			MaintenanceWindow: true,
+			Location:          "Europe/Berlin",
+		}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid invalid schedules", func() {
+		config.Rule.Schedule = &acl.Schedule{
+			Windows: []acl.TimeWindow{
+				{Days: []string{"Mon", "monday"}, Start: "8am", End: "18:00"},
+				{Start: "08:00", End: "08:00"},
+			},
+			Location: "Europe/Nowhere",
+		}
+		config.Rules = []acl.ACLRule{{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}, Schedule: &acl.Schedule{Location: "UTC"}}}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ContainElements(
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.schedule.location"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.schedule.windows[0].days[1]"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.schedule.windows[0].start"),
+			matchError(field.ErrorTypeInvalid, "providerConfig.rule.schedule.windows[1].end"),
+			matchError(field.ErrorTypeRequired, "providerConfig.rules[0].schedule.windows"),
+		))
+	})
+
+	It("should allow the supported modes", func() {
+		for _, mode := range []string{"", acl.ModeEnforce, acl.ModeShadow} {
+			config.Mode = mode
+			Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+		}
+	})
+
+	It("should forbid unknown modes", func() {
+		config.Mode = "shadow"
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeNotSupported, "providerConfig.mode"),
+		))
+	})
+
+	It("should forbid too many CIDRs in a single rule", func() {
+		config.Rule.Cidrs = nil
+		for i := range maxAllowedCIDRs + 1 {
+			config.Rule.Cidrs = append(config.Rule.Cidrs, fmt.Sprintf("10.%d.0.0/16", i))
+		}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeTooMany, "providerConfig.rule.cidrs"),
+		))
+	})
+
+	It("should forbid too many CIDRs across a list of rules", func() {
+		config = &acl.ACLConfig{}
+		for i := range maxAllowedCIDRs + 1 {
+			config.Rules = append(config.Rules, acl.ACLRule{
+				Action: "ALLOW",
+				Type:   "remote_ip",
+				Cidrs:  []string{fmt.Sprintf("10.%d.0.0/16", i)},
+			})
+		}
+
+		Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeTooMany, "providerConfig.rules"),
+		))
+	})
+
+	It("should not limit the number of CIDRs if no maximum is given", func() {
+		config.Rule.Cidrs = nil
+		for i := range maxAllowedCIDRs + 1 {
+			config.Rule.Cidrs = append(config.Rule.Cidrs, fmt.Sprintf("10.%d.0.0/16", i))
+		}
+
+		Expect(ValidateACLConfig(config, 0, fldPath)).To(BeEmpty())
+	})
+
+	Context("endpoint overrides", func() {
+		It("should allow endpoint overrides with a default rule", func() {
+			config.APIServer = &acl.EndpointConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+			}
+
+			Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow overrides for all endpoints without a default rule", func() {
+			endpoint := &acl.EndpointConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+			}
+			config = &acl.ACLConfig{
+				APIServer: endpoint,
+				VPN:       endpoint,
+				HTTPProxy: endpoint,
+				Ingress:   &acl.IngressConfig{EndpointConfig: *endpoint},
+			}
+
+			Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+		})
+
+		It("should require rules for every endpoint without a default rule", func() {
+			config = &acl.ACLConfig{
+				APIServer: &acl.EndpointConfig{
+					Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+				},
+			}
+
+			Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeRequired, "providerConfig.vpn"),
+				matchError(field.ErrorTypeRequired, "providerConfig.httpProxy"),
+				matchError(field.ErrorTypeRequired, "providerConfig.ingress"),
+			))
+		})
+
+		It("should return the errors of invalid endpoint overrides", func() {
+			config.VPN = &acl.EndpointConfig{
+				Rule:  &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}},
+				Rules: []acl.ACLRule{{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8"}}},
+			}
+			config.Ingress = &acl.IngressConfig{
+				EndpointConfig: acl.EndpointConfig{
+					Rule: &acl.ACLRule{Action: "ALLOW", Type: "nonexistent", Cidrs: []string{"10.0.0.0/8"}},
+				},
+			}
+
+			Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeForbidden, "providerConfig.vpn.rules"),
+				matchError(field.ErrorTypeNotSupported, "providerConfig.ingress.rule.type"),
+			))
+		})
+
+		It("should forbid too many CIDRs across the overrides", func() {
+			config.APIServer = &acl.EndpointConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "10.1.0.0/16"}},
+			}
+
+			Expect(ValidateACLConfig(config, 2, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeTooMany, "providerConfig"),
+			))
+		})
+	})
+
+	Context("ingress components", func() {
+		It("should allow rules for known components", func() {
+			config.Ingress = &acl.IngressConfig{
+				Components: map[string]acl.EndpointConfig{
+					acl.IngressComponentPlutono: {
+						Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0"}},
+					},
+				},
+			}
+
+			Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(BeEmpty())
+		})
+
+		It("should forbid unknown components and components without rules", func() {
+			config.Ingress = &acl.IngressConfig{
+				Components: map[string]acl.EndpointConfig{
+					"grafana": {
+						Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0"}},
+					},
+					acl.IngressComponentPrometheus: {},
+				},
+			}
+
+			Expect(ValidateACLConfig(config, maxAllowedCIDRs, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeNotSupported, "providerConfig.ingress.components"),
+				matchError(field.ErrorTypeRequired, "providerConfig.ingress.components[prometheus].rule"),
+			))
+		})
+
+		It("should count the CIDRs of the components", func() {
+			config.Ingress = &acl.IngressConfig{
+				Components: map[string]acl.EndpointConfig{
+					acl.IngressComponentPrometheus: {
+						Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "10.2.0.0/16"}},
+					},
+				},
+			}
+
+			Expect(ValidateACLConfig(config, 2, fldPath)).To(ConsistOf(
+				matchError(field.ErrorTypeTooMany, "providerConfig"),
+			))
+		})
+	})
+})
+
+var _ = Describe("ValidateACLConfigUpdate", func() {
+	var (
+		fldPath *field.Path
+		config  *acl.ACLConfig
+	)
+
+	BeforeEach(func() {
+		fldPath = field.NewPath("providerConfig")
+		config = &acl.ACLConfig{
+			Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "10.1.0.0/16", "10.2.0.0/16"}},
+		}
+	})
+
+	It("should allow unchanged CIDRs exceeding the maximum", func() {
+		Expect(ValidateACLConfigUpdate(config, config.AllCIDRs(), 2, time.Now(), fldPath)).To(BeEmpty())
+	})
+
+	It("should allow removing CIDRs and changing their notation", func() {
+		oldCIDRs := []string{"10.0.0.0/8", "10.1.0.0/16", "10.2.0.0/16", "10.3.0.0/16"}
+		config.Rule.Cidrs = []string{"10.1.2.3/8", "::ffff:10.1.0.0/112", "10.2.0.0/16"}
+
+		Expect(ValidateACLConfigUpdate(config, oldCIDRs, 2, time.Now(), fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid adding CIDRs", func() {
+		oldCIDRs := []string{"10.0.0.0/8", "10.1.0.0/16"}
+
+		Expect(ValidateACLConfigUpdate(config, oldCIDRs, 2, time.Now(), fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeTooMany, "providerConfig.rule.cidrs"),
+		))
+	})
+
+	It("should forbid replacing CIDRs", func() {
+		oldCIDRs := []string{"10.0.0.0/8", "10.1.0.0/16", "10.3.0.0/16"}
+
+		Expect(ValidateACLConfigUpdate(config, oldCIDRs, 2, time.Now(), fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeTooMany, "providerConfig.rule.cidrs"),
+		))
+	})
+
+	It("should forbid duplicating CIDRs", func() {
+		oldCIDRs := []string{"10.0.0.0/8", "10.1.0.0/16", "10.2.0.0/16"}
+		config.Rule.Cidrs = append(config.Rule.Cidrs, "10.2.0.0/16")
+
+		Expect(ValidateACLConfigUpdate(config, oldCIDRs, 2, time.Now(), fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeTooMany, "providerConfig.rule.cidrs"),
+		))
+	})
+})
+
+var _ = Describe("ValidateRenderedCIDRs", func() {
+	var (
+		fldPath *field.Path
+		config  *acl.ACLConfig
+	)
+
+	BeforeEach(func() {
+		fldPath = field.NewPath("providerConfig")
+		config = &acl.ACLConfig{
+			Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "10.1.0.0/16"}},
+			VPN: &acl.EndpointConfig{Rules: []acl.ACLRule{
+				{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.0.0.0/8", "10.1.0.0/16"}},
+				{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.2.0.0/16"}},
+			}},
+		}
+	})
+
+	It("should allow endpoints with at most the maximum number of CIDRs", func() {
+		Expect(ValidateRenderedCIDRs(config, 3, fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid endpoints with more CIDRs", func() {
+		Expect(ValidateRenderedCIDRs(config, 2, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+			"Type":   Equal(field.ErrorTypeForbidden),
+			"Field":  Equal("providerConfig"),
+			"Detail": Equal("the rules of the vpn endpoint resolve to 3 CIDRs, at most 2 are allowed"),
+		}))))
+	})
+
+	It("should not limit the CIDRs if the maximum is zero", func() {
+		Expect(ValidateRenderedCIDRs(config, 0, fldPath)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ValidateCIDREntryExpiry", func() {
+	var (
+		fldPath *field.Path
+		now     time.Time
+		config  *acl.ACLConfig
+	)
+
+	BeforeEach(func() {
+		fldPath = field.NewPath("providerConfig")
+		now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		config = &acl.ACLConfig{
+			Rules: []acl.ACLRule{{Action: "ALLOW", Type: "remote_ip", CIDREntries: []acl.CIDREntry{
+				{CIDR: "10.1.0.0/16"},
+				{CIDR: "10.2.0.0/16", ExpiresAt: &metav1.Time{Time: now.Add(time.Hour)}},
+				{CIDR: "10.3.0.0/16", ExpiresAt: &metav1.Time{Time: now}},
+			}}},
+		}
+	})
+
+	It("should forbid expired entries", func() {
+		Expect(ValidateCIDREntryExpiry(config, nil, now, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeInvalid, "providerConfig.rules[0].cidrEntries[2].expiresAt"),
+		))
+	})
+
+	It("should allow entries which expired since they were added", func() {
+		oldConfig := config.DeepCopy()
+
+		Expect(ValidateCIDREntryExpiry(config, oldConfig, now.Add(2*time.Hour), fldPath)).To(BeEmpty())
+	})
+
+	It("should forbid changed expired entries", func() {
+		oldConfig := config.DeepCopy()
+		config.Rules[0].CIDREntries[2].CIDR = "10.4.0.0/16"
+
+		Expect(ValidateCIDREntryExpiry(config, oldConfig, now, fldPath)).To(ConsistOf(
+			matchError(field.ErrorTypeInvalid, "providerConfig.rules[0].cidrEntries[2].expiresAt"),
+		))
+	})
+})
+
+func matchError(errorType field.ErrorType, fieldPath string) gomegatypes.GomegaMatcher {
+	return PointTo(MatchFields(IgnoreExtras, Fields{
+		"Type":  Equal(errorType),
+		"Field": Equal(fieldPath),
+	}))
+}
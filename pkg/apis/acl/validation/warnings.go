@@ -0,0 +1,113 @@
+package validation
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+var (
+	// privatePrefixes are address ranges which are not routed on the internet,
+	// so connections through the ingress gateway never originate from them.
+	privatePrefixes = []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("100.64.0.0/10"),
+		netip.MustParsePrefix("127.0.0.0/8"),
+		netip.MustParsePrefix("169.254.0.0/16"),
+		netip.MustParsePrefix("172.16.0.0/12"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+		netip.MustParsePrefix("::1/128"),
+		netip.MustParsePrefix("fc00::/7"),
+		netip.MustParsePrefix("fe80::/10"),
+	}
+
+	// minPrefixLengths are the prefix lengths per address family below which a
+	// CIDR is considered overly broad. Allowing or denying all addresses with a
+	// prefix length of zero is considered intentional.
+	minPrefixLengths = map[int]int{
+		32:  8,
+		128: 16,
+	}
+)
+
+// GetWarnings returns warnings for rules of the given ACLConfig, which are
+// valid but most likely not what the user intended:
+//   - CIDRs of a rule overlapping each other
+//   - CIDRs covered by the alwaysAllowedCIDRs, which are allowed regardless of
+//     the rules
+//   - private ranges in remote_ip rules, which are never seen as the remote
+//     address of a connection through the ingress gateway
+//   - overly broad CIDRs (e.g. /1 to /7 for IPv4)
+//
+// The config is expected to be valid, invalid CIDRs are skipped.
+func GetWarnings(config *acl.ACLConfig, alwaysAllowedCIDRs []string, fldPath *field.Path) []string {
+	var alwaysAllowed []netip.Prefix
+	for _, cidr := range alwaysAllowedCIDRs {
+		if prefix, err := aclhelper.ParseCanonicalPrefix(cidr); err == nil {
+			alwaysAllowed = append(alwaysAllowed, prefix)
+		}
+	}
+
+	var warnings []string
+	forEachRule(config, fldPath, func(rule *acl.ACLRule, rulePath *field.Path) {
+		warnings = append(warnings, ruleWarnings(rule, alwaysAllowed, rulePath)...)
+	})
+	return warnings
+}
+
+func ruleWarnings(rule *acl.ACLRule, alwaysAllowed []netip.Prefix, fldPath *field.Path) []string {
+	var (
+		warnings []string
+		cidrs    = ruleCIDRs(rule, fldPath)
+		prefixes = make([]netip.Prefix, len(cidrs))
+		allow    = strings.EqualFold(rule.Action, "ALLOW")
+		remoteIP = strings.EqualFold(rule.Type, "remote_ip")
+	)
+
+	for i, c := range cidrs {
+		cidr, cidrPath := c.cidr, c.path
+		prefix, err := aclhelper.ParseCanonicalPrefix(cidr)
+		if err != nil {
+			continue
+		}
+		prefixes[i] = prefix
+
+		for j := range i {
+			if prefixes[j].IsValid() && prefixes[j].Overlaps(prefix) {
+				warnings = append(warnings, fmt.Sprintf("%s: %q overlaps with %q (%s)", cidrPath, cidr, cidrs[j].cidr, cidrs[j].path))
+			}
+		}
+
+		if covering, ok := coveringPrefix(alwaysAllowed, prefix); ok {
+			if allow {
+				warnings = append(warnings, fmt.Sprintf("%s: %q is always allowed, as it is covered by %q", cidrPath, cidr, covering))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("%s: %q cannot be denied, as it is covered by the always allowed %q", cidrPath, cidr, covering))
+			}
+		} else if private, ok := coveringPrefix(privatePrefixes, prefix); ok && remoteIP {
+			warnings = append(warnings, fmt.Sprintf("%s: %q is part of the private range %q, connections through the ingress gateway never originate from it", cidrPath, cidr, private))
+		}
+
+		if bits := prefix.Bits(); bits > 0 && bits < minPrefixLengths[prefix.Addr().BitLen()] {
+			warnings = append(warnings, fmt.Sprintf("%s: %q is very broad, it covers 2^%d addresses", cidrPath, cidr, prefix.Addr().BitLen()-bits))
+		}
+	}
+
+	return warnings
+}
+
+// coveringPrefix returns the first of the given prefixes covering the prefix
+// completely.
+func coveringPrefix(prefixes []netip.Prefix, prefix netip.Prefix) (netip.Prefix, bool) {
+	for _, p := range prefixes {
+		if p.Bits() <= prefix.Bits() && p.Contains(prefix.Addr()) {
+			return p, true
+		}
+	}
+	return netip.Prefix{}, false
+}
@@ -0,0 +1,113 @@
+package validation
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+)
+
+var _ = Describe("GetWarnings", func() {
+	var (
+		fldPath            *field.Path
+		alwaysAllowedCIDRs []string
+	)
+
+	BeforeEach(func() {
+		fldPath = field.NewPath("providerConfig")
+		alwaysAllowedCIDRs = []string{"10.250.0.0/16", "2001:db8::/32"}
+	})
+
+	It("should not return warnings for reasonable rules", func() {
+		config := &acl.ACLConfig{
+			Rules: []acl.ACLRule{
+				{Action: "DENY", Type: "remote_ip", Cidrs: []string{"203.0.113.7/32"}},
+				{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"203.0.113.0/24", "198.51.100.0/24"}},
+				{Action: "DENY", Type: "remote_ip", Cidrs: []string{"0.0.0.0/0"}},
+			},
+		}
+
+		Expect(GetWarnings(config, alwaysAllowedCIDRs, fldPath)).To(BeEmpty())
+	})
+
+	It("should warn about overlapping CIDRs of a rule", func() {
+		config := &acl.ACLConfig{
+			Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"203.0.113.0/25", "198.51.100.0/24", "203.0.113.0/24"}},
+		}
+
+		Expect(GetWarnings(config, alwaysAllowedCIDRs, fldPath)).To(ConsistOf(
+			`providerConfig.rule.cidrs[2]: "203.0.113.0/24" overlaps with "203.0.113.0/25" (providerConfig.rule.cidrs[0])`,
+		))
+	})
+
+	It("should warn about CIDR entries overlapping the CIDRs of a rule", func() {
+		config := &acl.ACLConfig{
+			Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"203.0.113.0/24"}, CIDREntries: []acl.CIDREntry{
+				{CIDR: "198.51.100.0/24"},
+				{CIDR: "203.0.113.128/25"},
+			}},
+		}
+
+		Expect(GetWarnings(config, alwaysAllowedCIDRs, fldPath)).To(ConsistOf(
+			`providerConfig.rule.cidrEntries[1].cidr: "203.0.113.128/25" overlaps with "203.0.113.0/24" (providerConfig.rule.cidrs[0])`,
+		))
+	})
+
+	It("should warn about CIDRs covered by the always allowed CIDRs", func() {
+		config := &acl.ACLConfig{
+			Rules: []acl.ACLRule{
+				{Action: "DENY", Type: "remote_ip", Cidrs: []string{"10.250.1.0/24"}},
+				{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"2001:db8:1::/48"}},
+			},
+		}
+
+		Expect(GetWarnings(config, alwaysAllowedCIDRs, fldPath)).To(ConsistOf(
+			`providerConfig.rules[0].cidrs[0]: "10.250.1.0/24" cannot be denied, as it is covered by the always allowed "10.250.0.0/16"`,
+			`providerConfig.rules[1].cidrs[0]: "2001:db8:1::/48" is always allowed, as it is covered by "2001:db8::/32"`,
+		))
+	})
+
+	It("should warn about private ranges in remote_ip rules only", func() {
+		config := &acl.ACLConfig{
+			Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"192.168.1.0/24", "fd00::/48"}},
+			APIServer: &acl.EndpointConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "direct_remote_ip", Cidrs: []string{"192.168.1.0/24"}},
+			},
+		}
+
+		Expect(GetWarnings(config, alwaysAllowedCIDRs, fldPath)).To(ConsistOf(
+			`providerConfig.rule.cidrs[0]: "192.168.1.0/24" is part of the private range "192.168.0.0/16", connections through the ingress gateway never originate from it`,
+			`providerConfig.rule.cidrs[1]: "fd00::/48" is part of the private range "fc00::/7", connections through the ingress gateway never originate from it`,
+		))
+	})
+
+	It("should warn about overly broad CIDRs", func() {
+		config := &acl.ACLConfig{
+			Rule: &acl.ACLRule{Action: "DENY", Type: "remote_ip", Cidrs: []string{"128.0.0.0/1", "64.0.0.0/8", "8000::/15", "2a00::/16"}},
+		}
+
+		Expect(GetWarnings(config, alwaysAllowedCIDRs, fldPath)).To(ConsistOf(
+			`providerConfig.rule.cidrs[0]: "128.0.0.0/1" is very broad, it covers 2^31 addresses`,
+			`providerConfig.rule.cidrs[2]: "8000::/15" is very broad, it covers 2^113 addresses`,
+		))
+	})
+
+	It("should return warnings for endpoints and ingress components", func() {
+		config := &acl.ACLConfig{
+			VPN: &acl.EndpointConfig{
+				Rule: &acl.ACLRule{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"10.250.0.0/24"}},
+			},
+			Ingress: &acl.IngressConfig{
+				Components: map[string]acl.EndpointConfig{
+					acl.IngressComponentPlutono: {Rules: []acl.ACLRule{{Action: "ALLOW", Type: "remote_ip", Cidrs: []string{"4.0.0.0/6"}}}},
+				},
+			},
+		}
+
+		Expect(GetWarnings(config, alwaysAllowedCIDRs, fldPath)).To(ConsistOf(
+			`providerConfig.vpn.rule.cidrs[0]: "10.250.0.0/24" is always allowed, as it is covered by "10.250.0.0/16"`,
+			`providerConfig.ingress.components[plutono].rules[0].cidrs[0]: "4.0.0.0/6" is very broad, it covers 2^26 addresses`,
+		))
+	})
+})
@@ -16,12 +16,15 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	extensionsconfigv1alpha1 "github.com/gardener/gardener/extensions/pkg/apis/config/v1alpha1"
 	extensionscmdcontroller "github.com/gardener/gardener/extensions/pkg/controller/cmd"
 	extensionshealthcheckcontroller "github.com/gardener/gardener/extensions/pkg/controller/healthcheck"
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/stackitcloud/gardener-extension-acl/pkg/controller"
 	controllerconfig "github.com/stackitcloud/gardener-extension-acl/pkg/controller/config"
@@ -33,13 +36,28 @@ const (
 	DefaultSyncPeriod = 30 * time.Second
 	// ChartPath is the path to the chart folder
 	ChartPath = "charts"
+	// DefaultHostsResolutionInterval is the default hosts-resolution-interval
+	DefaultHostsResolutionInterval = 5 * time.Minute
+	// DefaultCIDRExpiryWarningHorizon is the default cidr-expiry-warning-horizon
+	DefaultCIDRExpiryWarningHorizon = 14 * 24 * time.Hour
+	// DefaultMaxRenderedCIDRs is the default max-rendered-cidrs
+	DefaultMaxRenderedCIDRs = 20000
 )
 
 // ExtensionOptions holds options related to the extension (not the extension controller)
 type ExtensionOptions struct {
-	HealthCheckSyncPeriod  time.Duration
-	ChartPath              string
-	AdditionalAllowedCIDRs []string
+	HealthCheckSyncPeriod    time.Duration
+	ChartPath                string
+	AdditionalAllowedCIDRs   []string
+	CIDRSetsConfigMap        string
+	HostsResolutionInterval  time.Duration
+	GeoIPCountriesFile       string
+	GeoIPASNsFile            string
+	CIDRExpiryWarningHorizon time.Duration
+	EnvoyStatsPort           int
+	MaxRenderedCIDRs         int
+
+	cidrSetsConfigMap types.NamespacedName
 }
 
 // AddFlags implements Flagger.AddFlags.
@@ -52,11 +70,60 @@ func (o *ExtensionOptions) AddFlags(fs *pflag.FlagSet) {
 		nil,
 		"List of IPs that will be added to the list of allowed CIDRs, e.g. '192.168.1.40/32,10.250.0.0/16'",
 	)
+	fs.StringVar(
+		&o.CIDRSetsConfigMap,
+		"cidr-sets-configmap",
+		"",
+		"ConfigMap containing the named CIDR sets rules can reference, in the form '<namespace>/<name>'",
+	)
+	fs.DurationVar(
+		&o.HostsResolutionInterval,
+		"hosts-resolution-interval",
+		DefaultHostsResolutionInterval,
+		"Interval in which the hosts of the rules are resolved again, 0 disables the periodic resolution",
+	)
+	fs.StringVar(
+		&o.GeoIPCountriesFile,
+		"geoip-countries-file",
+		"",
+		"MMDB (*.mmdb) or CSV file mapping address ranges to country codes, e.g. the GeoLite2 Country or DB-IP IP to Country Lite database",
+	)
+	fs.StringVar(
+		&o.GeoIPASNsFile,
+		"geoip-asns-file",
+		"",
+		"MMDB (*.mmdb) or CSV file mapping address ranges to ASNs, e.g. the GeoLite2 ASN or DB-IP IP to ASN Lite database",
+	)
+	fs.DurationVar(
+		&o.CIDRExpiryWarningHorizon,
+		"cidr-expiry-warning-horizon",
+		DefaultCIDRExpiryWarningHorizon,
+		"CIDR entries expiring within this duration are reported in the status of the extensions",
+	)
+	fs.IntVar(
+		&o.EnvoyStatsPort,
+		"envoy-stats-port",
+		0,
+		"Port of the Envoy stats in the Prometheus format of the istio-ingressgateway pods to scrape the denied connections from, usually 15090, 0 disables the acl_denied_total metric",
+	)
+	fs.IntVar(
+		&o.MaxRenderedCIDRs,
+		"max-rendered-cidrs",
+		DefaultMaxRenderedCIDRs,
+		"Maximum number of CIDRs rendered per endpoint after resolving CIDR sets, hosts, countries and ASNs, 0 disables the limit",
+	)
 }
 
 // Complete implements Completer.Complete.
 func (o *ExtensionOptions) Complete() error {
 	// TODO validate mandatory input options
+	if o.CIDRSetsConfigMap != "" {
+		namespace, name, ok := strings.Cut(o.CIDRSetsConfigMap, "/")
+		if !ok || namespace == "" || name == "" {
+			return fmt.Errorf("invalid value %q of --cidr-sets-configmap, expected '<namespace>/<name>'", o.CIDRSetsConfigMap)
+		}
+		o.cidrSetsConfigMap = types.NamespacedName{Namespace: namespace, Name: name}
+	}
 	return nil
 }
 
@@ -70,6 +137,13 @@ func (o *ExtensionOptions) Apply(config *controllerconfig.Config) {
 	// TODO pass controller options from extensionoptions to config param
 	config.ChartPath = o.ChartPath
 	config.AdditionalAllowedCIDRs = o.AdditionalAllowedCIDRs
+	config.CIDRSetsConfigMap = o.cidrSetsConfigMap
+	config.HostsResolutionInterval = o.HostsResolutionInterval
+	config.GeoIPCountriesFile = o.GeoIPCountriesFile
+	config.GeoIPASNsFile = o.GeoIPASNsFile
+	config.CIDRExpiryWarningHorizon = o.CIDRExpiryWarningHorizon
+	config.EnvoyStatsPort = o.EnvoyStatsPort
+	config.MaxRenderedCIDRs = o.MaxRenderedCIDRs
 }
 
 // ApplyHealthCheckConfig applies the ExtensionOptions to the passed HealthCheckConfig.
@@ -40,16 +40,22 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	"github.com/stackitcloud/gardener-extension-acl/charts"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/validation"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/controller/config"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/envoyfilters"
-	"github.com/stackitcloud/gardener-extension-acl/pkg/extensionspec"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/helper"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/imagevector"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/resolver"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
@@ -73,26 +79,40 @@ const (
 
 // Error variables for controller pkg
 var (
-	ErrSpecAction            = errors.New("action must either be 'ALLOW' or 'DENY'")
-	ErrSpecRule              = errors.New("rule must be present")
-	ErrSpecType              = errors.New("type must either be 'direct_remote_ip', 'remote_ip' or 'source_ip'")
-	ErrSpecCIDR              = errors.New("CIDRs must not be empty")
-	ErrSpecTooManyCIDRs      = errors.New("number of CIDRs exceeds the maximum allowed")
 	ErrNoAdvertisedAddresses = errors.New("advertised addresses are not available, likely because cluster creation has not yet completed")
 )
 
 // ExtensionState contains the State of the Extension
 type ExtensionState struct {
 	IstioNamespace *string `json:"istioNamespace"`
+	// CIDRs are the CIDRs of the last successfully reconciled ACLConfig. They
+	// are used to accept configs exceeding a lowered MaxAllowedCIDRs, as long
+	// as they do not grow.
+	CIDRs []string `json:"cidrs,omitempty"`
+	// Hosts are the CIDRs of the addresses of the hosts of the last successfully
+	// reconciled ACLConfig. They are used if a host cannot be resolved.
+	Hosts map[string][]string `json:"hosts,omitempty"`
+	// BreakGlass is the break-glass access granted with
+	// AnnotationBreakGlassCIDRs.
+	BreakGlass *BreakGlassState `json:"breakGlass,omitempty"`
+	// InvalidBreakGlass are the values of the break-glass annotations last
+	// reported as invalid, so they are only reported once.
+	InvalidBreakGlass string `json:"invalidBreakGlass,omitempty"`
 }
 
-// NewActuator returns an actuator responsible for Extension resources.
-func NewActuator(mgr manager.Manager, cfg config.Config) extension.Actuator {
+// NewActuator returns an actuator responsible for Extension resources. geoIP
+// is nil if no GeoIP database is configured.
+func NewActuator(mgr manager.Manager, cfg config.Config, geoIP *geoIPReloader, requeuer *scheduleRequeuer) extension.Actuator {
 	return &actuator{
 		extensionConfig: cfg,
 		client:          mgr.GetClient(),
 		config:          mgr.GetConfig(),
 		decoder:         serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict).UniversalDecoder(),
+		resolver:        net.DefaultResolver,
+		geoIP:           geoIP,
+		requeuer:        requeuer,
+		clock:           clock.RealClock{},
+		recorder:        mgr.GetEventRecorder(Type + suffix),
 	}
 }
 
@@ -101,6 +121,11 @@ type actuator struct {
 	config          *rest.Config
 	decoder         runtime.Decoder
 	extensionConfig config.Config
+	resolver        resolver.Resolver
+	geoIP           *geoIPReloader
+	requeuer        *scheduleRequeuer
+	clock           clock.PassiveClock
+	recorder        events.EventRecorder
 }
 
 // Reconcile the Extension resource.
@@ -112,16 +137,78 @@ func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, ex *extension
 		return err
 	}
 
-	extSpec := &extensionspec.ExtensionSpec{}
-	if ex.Spec.ProviderConfig != nil && ex.Spec.ProviderConfig.Raw != nil {
-		if err := json.Unmarshal(ex.Spec.ProviderConfig.Raw, &extSpec); err != nil {
+	extSpec, err := aclhelper.DecodeACLConfig(a.decoder, ex.Spec.ProviderConfig)
+	if err != nil {
+		return fmt.Errorf("failed to decode provider config: %w", err)
+	}
+
+	extState, err := getExtensionState(ex)
+	if err != nil {
+		return err
+	}
+
+	// the CIDRs of referenced resources are validated and counted like the
+	// CIDRs of the providerConfig
+	if err := a.resolveCIDRsFrom(ctx, ex.GetNamespace(), cluster, extSpec); err != nil {
+		return err
+	}
+
+	// the CIDRs of referenced CIDR sets are managed by the operator and
+	// therefore not recorded for the grandfathering of the CIDR limit
+	now := a.clock.Now()
+	specCIDRs := extSpec.UnexpiredCIDRs(now)
+
+	// validate the ACLConfig
+	if errs := validation.ValidateACLConfigUpdate(
+		extSpec, grandfatheredCIDRs(ex, extState, specCIDRs), a.extensionConfig.MaxAllowedCIDRs, now, field.NewPath("providerConfig"),
+	); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	if err := a.resolveCIDRSets(ctx, extSpec); err != nil {
+		return err
+	}
+	if err := a.resolveHosts(ctx, log, extSpec, extState); err != nil {
+		return err
+	}
+	if err := a.resolveGeoIP(extSpec); err != nil {
+		return err
+	}
+
+	// only the unexpired CIDR entries, the rules active right now and the
+	// unexpired break-glass access are rendered, the Extension is reconciled
+	// again when the next entry or the access expires or a schedule starts or
+	// ends
+	extStatus, nextExpiry := a.resolveCIDREntries(extSpec, now)
+
+	// countries, ASNs and CIDR sets can resolve to far more CIDRs than the
+	// providerConfig contains, so the rendered CIDRs are limited separately
+	if errs := validation.ValidateRenderedCIDRs(
+		extSpec, a.extensionConfig.MaxRenderedCIDRs, field.NewPath("providerConfig"),
+	); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+
+	nextTransition, err := aclhelper.ApplySchedules(extSpec, now, getMaintenanceWindow(cluster))
+	if err != nil {
+		return fmt.Errorf("failed to evaluate schedules: %w", err)
+	}
+	breakGlassCIDRs, breakGlassExpiry, breakGlassEvents := reconcileBreakGlass(ex, extState, now)
+	// changes of the break-glass access are saved before they are rendered, so
+	// a failing reconciliation neither extends the access when it is retried
+	// nor emits its events twice
+	if len(breakGlassEvents) > 0 {
+		if err := a.updateState(ctx, ex, extState); err != nil {
 			return err
 		}
+		for _, event := range breakGlassEvents {
+			a.recorder.Eventf(ex, nil, event.eventType, event.reason, event.action, "%s", event.note)
+		}
 	}
-	// validate the ExtensionSpec
-	if err := ValidateExtensionSpec(extSpec, a.extensionConfig.MaxAllowedCIDRs); err != nil {
-		return err
+	requeueAfter := time.Duration(-1)
+	if next := earliest(nextExpiry, nextTransition, breakGlassExpiry); !next.IsZero() {
+		requeueAfter = next.Sub(now)
 	}
+	a.requeuer.requeueAfter(client.ObjectKeyFromObject(ex), requeueAfter)
 
 	istioNamespace, istioLabels, err := a.findIstioNamespaceForExtension(ctx, ex)
 	if err != nil {
@@ -133,11 +220,6 @@ func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, ex *extension
 		return err
 	}
 
-	extState, err := getExtensionState(ex)
-	if err != nil {
-		return err
-	}
-
 	hosts := make([]string, 0)
 	if len(cluster.Shoot.Status.AdvertisedAddresses) < 1 {
 		return ErrNoAdvertisedAddresses
@@ -170,6 +252,9 @@ func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, ex *extension
 		alwaysAllowedCIDRs = append(alwaysAllowedCIDRs, a.extensionConfig.AdditionalAllowedCIDRs...)
 	}
 
+	// break-glass access bypasses the rules of all endpoints
+	alwaysAllowedCIDRs = append(alwaysAllowedCIDRs, breakGlassCIDRs...)
+
 	// Gardener supports workerless Shoots. These don't have an associated
 	// Infrastructure object and don't need Node- or Pod-specific CIDRs to be
 	// allowed. Therefore, skip these steps for workerless Shoots.
@@ -200,59 +285,16 @@ func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, ex *extension
 	}
 
 	extState.IstioNamespace = &istioNamespace
+	extState.CIDRs = specCIDRs
 
-	return a.updateStatus(ctx, ex, extState)
-}
-
-// ValidateExtensionSpec checks if the ExtensionSpec exists, and if its action,
-// type and CIDRs are valid. It also checks if the number of CIDRs does not exceed maxAllowedCIDRs.
-func ValidateExtensionSpec(spec *extensionspec.ExtensionSpec, maxAllowedCIDRs int) error {
-	rule := spec.Rule
-
-	if rule == nil {
-		return ErrSpecRule
-	}
-
-	// action
-	a := strings.ToLower(rule.Action)
-	if a != "allow" && a != "deny" {
-		return ErrSpecAction
-	}
-
-	// type
-	t := strings.ToLower(rule.Type)
-	if t != "direct_remote_ip" &&
-		t != "remote_ip" &&
-		t != "source_ip" {
-		return ErrSpecType
-	}
-
-	// cidrs
-	if len(rule.Cidrs) < 1 {
-		return ErrSpecCIDR
-	}
-
-	if maxAllowedCIDRs > 0 && len(rule.Cidrs) > maxAllowedCIDRs {
-		return ErrSpecTooManyCIDRs
-	}
-
-	for ii := range rule.Cidrs {
-		_, mask, err := net.ParseCIDR(rule.Cidrs[ii])
-		if err != nil {
-			return err
-		}
-		if mask == nil {
-			return ErrSpecCIDR
-		}
-	}
-
-	return nil
+	return a.updateStatus(ctx, ex, extState, extStatus)
 }
 
 // Delete the Extension resource.
 func (a *actuator) Delete(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension) error {
 	namespace := ex.GetNamespace()
 	log.Info("Component is being deleted", "component", "", "namespace", namespace)
+	a.requeuer.requeueAfter(client.ObjectKeyFromObject(ex), -1)
 
 	return a.deleteSeedResources(ctx, log, namespace)
 }
@@ -276,7 +318,7 @@ func (a *actuator) createSeedResources(
 	ctx context.Context,
 	log logr.Logger,
 	namespace string,
-	spec *extensionspec.ExtensionSpec,
+	spec *acl.ACLConfig,
 	cluster *controller.Cluster,
 	hosts []string,
 	shootSpecificCIDRs []string,
@@ -288,18 +330,21 @@ func (a *actuator) createSeedResources(
 
 	alwaysAllowedCIDRs = append(alwaysAllowedCIDRs, shootSpecificCIDRs...)
 
+	// In shadow mode, the rules are only evaluated and counted, but not enforced.
+	shadow := spec.Mode == acl.ModeShadow
+
 	apiEnvoyFilterSpec, err := envoyfilters.BuildAPIEnvoyFilterSpecForHelmChart(
-		spec.Rule, hosts, alwaysAllowedCIDRs, istioLabels,
+		cluster, spec.GetAPIServerRules(), hosts, alwaysAllowedCIDRs, istioLabels, shadow,
 	)
 	if err != nil {
 		return err
 	}
 
 	vpnEnvoyFilterSpec := envoyfilters.BuildVPNEnvoyFilterSpecForHelmChart(
-		cluster, spec.Rule, alwaysAllowedCIDRs, istioLabels,
+		cluster, spec.GetVPNRules(), alwaysAllowedCIDRs, istioLabels, shadow,
 	)
 	httpProxyEnvoyFilterSpec := envoyfilters.BuildHTTPProxyEnvoyFilterSpecForHelmChart(
-		cluster, spec.Rule, alwaysAllowedCIDRs, istioLabels,
+		cluster, spec.GetHTTPProxyRules(), alwaysAllowedCIDRs, istioLabels, shadow,
 	)
 
 	cfg := map[string]interface{}{
@@ -318,7 +363,7 @@ func (a *actuator) createSeedResources(
 		// https://github.com/gardener/gardener/pull/9038).
 		// If it doesn't exist yet, we can't apply ACLs to shoot ingresses.
 		ingressEnvoyFilterSpec := envoyfilters.BuildIngressEnvoyFilterSpecForHelmChart(
-			cluster, spec.Rule, alwaysAllowedCIDRs, defaultLabels)
+			cluster, spec.GetIngressRules(), spec.GetIngressComponentRules(), alwaysAllowedCIDRs, defaultLabels, shadow)
 
 		cfg["ingressEnvoyFilterSpec"] = ingressEnvoyFilterSpec
 	}
@@ -386,18 +431,51 @@ func (a *actuator) updateStatus(
 	ctx context.Context,
 	ex *extensionsv1alpha1.Extension,
 	state *ExtensionState,
+	status *ExtensionStatus,
 ) error {
 	stateJSON, err := json.Marshal(state)
 	if err != nil {
 		return err
 	}
+	statusJSON, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
 
 	patch := client.MergeFrom(ex.DeepCopy())
 
 	ex.Status.State = &runtime.RawExtension{Raw: stateJSON}
+	ex.Status.ProviderStatus = &runtime.RawExtension{Raw: statusJSON}
 	return a.client.Status().Patch(ctx, ex, patch)
 }
 
+// updateState saves the given ExtensionState without changing the provider
+// status of the Extension.
+func (a *actuator) updateState(ctx context.Context, ex *extensionsv1alpha1.Extension, state *ExtensionState) error {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(ex.DeepCopy())
+
+	ex.Status.State = &runtime.RawExtension{Raw: stateJSON}
+	return a.client.Status().Patch(ctx, ex, patch)
+}
+
+// grandfatheredCIDRs returns the CIDRs the ACLConfig of the given Extension may
+// keep even if they exceed MaxAllowedCIDRs, see
+// validation.ValidateACLConfigUpdate. Extensions reconciled before their CIDRs
+// were recorded in the state keep their current CIDRs, as the limit was enforced
+// by the admission webhook when they were set and a lowered limit must not make
+// them fail after an upgrade of the extension.
+func grandfatheredCIDRs(ex *extensionsv1alpha1.Extension, extState *ExtensionState, specCIDRs []string) []string {
+	if extState.CIDRs == nil && ex.Status.LastOperation != nil {
+		return specCIDRs
+	}
+	return extState.CIDRs
+}
+
 func getExtensionState(ex *extensionsv1alpha1.Extension) (*ExtensionState, error) {
 	extState := &ExtensionState{}
 	if ex.Status.State != nil && ex.Status.State.Raw != nil {
@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
 	. "github.com/gardener/gardener/pkg/utils/test/matchers"
@@ -13,12 +17,19 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
+	testclock "k8s.io/utils/clock/testing"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	aclv1alpha1 "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/v1alpha1"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/controller/config"
-	"github.com/stackitcloud/gardener-extension-acl/pkg/envoyfilters"
-	"github.com/stackitcloud/gardener-extension-acl/pkg/extensionspec"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/geoip"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/resolver"
 )
 
 var _ = Describe("actuator test", func() {
@@ -73,8 +84,8 @@ var _ = Describe("actuator test", func() {
 
 	Describe("reconciliation of an ACL extension object", func() {
 		It("should create managed resource containing acl-api-shoot and acl-vpn-shoot EnvoyFilter object", func() {
-			extSpec := extensionspec.ExtensionSpec{
-				Rule: &envoyfilters.ACLRule{
+			extSpec := aclv1alpha1.ACLConfig{
+				Rule: &aclv1alpha1.ACLRule{
 					Cidrs:  []string{"1.2.3.4/24"},
 					Action: "ALLOW",
 					Type:   "remote_ip",
@@ -96,10 +107,82 @@ var _ = Describe("actuator test", func() {
 			Expect(secret.Data["seed"]).To(ContainSubstring("acl-vpn-" + shootNamespace1))
 		})
 
+		It("should accept a provider config with apiVersion and kind", func() {
+			extSpec := aclv1alpha1.ACLConfig{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: aclv1alpha1.SchemeGroupVersion.String(),
+					Kind:       "ACLConfig",
+				},
+				Rule: &aclv1alpha1.ACLRule{
+					Cidrs:  []string{"1.2.3.4/24"},
+					Action: "ALLOW",
+				},
+			}
+			extSpecJSON, err := json.Marshal(extSpec)
+			Expect(err).NotTo(HaveOccurred())
+			ext := createNewExtension(shootNamespace1, extSpecJSON)
+			Expect(ext).To(Not(BeNil()))
+
+			Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+			mr := &v1alpha1.ManagedResource{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+			Expect(secret.Data["seed"]).To(ContainSubstring("1.2.3.4"))
+			Expect(secret.Data["seed"]).To(ContainSubstring("remote_ip"))
+		})
+
+		It("should reject a provider config with unknown fields", func() {
+			ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidr":["1.2.3.4/24"],"type":"remote_ip"}}`))
+			Expect(ext).To(Not(BeNil()))
+
+			Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring(`unknown field "rule.cidr"`)))
+		})
+
+		It("should return all validation errors of the provider config", func() {
+			ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"banana","cidrs":["1.2.3.4/24"],"type":"potato"}}`))
+			Expect(ext).To(Not(BeNil()))
+
+			err := a.Reconcile(ctx, logger, ext)
+			Expect(err).To(MatchError(ContainSubstring("providerConfig.rule.action")))
+			Expect(err).To(MatchError(ContainSubstring("providerConfig.rule.type")))
+		})
+
+		It("should use the endpoint specific rules and fall back to the default rule", func() {
+			extSpec := aclv1alpha1.ACLConfig{
+				Rule: &aclv1alpha1.ACLRule{
+					Cidrs:  []string{"1.2.3.4/24"},
+					Action: "ALLOW",
+					Type:   "remote_ip",
+				},
+				APIServer: &aclv1alpha1.EndpointConfig{
+					Rule: &aclv1alpha1.ACLRule{
+						Cidrs:  []string{"5.6.7.8/32"},
+						Action: "ALLOW",
+						Type:   "remote_ip",
+					},
+				},
+			}
+			extSpecJSON, err := json.Marshal(extSpec)
+			Expect(err).NotTo(HaveOccurred())
+			ext := createNewExtension(shootNamespace1, extSpecJSON)
+			Expect(ext).To(Not(BeNil()))
+
+			Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+			mr := &v1alpha1.ManagedResource{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+			Expect(secret.Data["seed"]).To(ContainSubstring("5.6.7.8"))
+			Expect(secret.Data["seed"]).To(ContainSubstring("1.2.3.4"))
+		})
+
 		It("should record the last seen istio namespace in the status of the extension object", func() {
 			// arrange
-			extSpec := extensionspec.ExtensionSpec{
-				Rule: &envoyfilters.ACLRule{
+			extSpec := aclv1alpha1.ACLConfig{
+				Rule: &aclv1alpha1.ACLRule{
 					Cidrs:  []string{"1.2.3.4/24"},
 					Action: "ALLOW",
 					Type:   "remote_ip",
@@ -126,6 +209,587 @@ var _ = Describe("actuator test", func() {
 			Expect(*extState.IstioNamespace).To(Equal(istioNamespace1))
 		})
 
+		It("should keep reconciling a provider config exceeding a lowered maximum number of CIDRs", func() {
+			ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24","5.6.7.8/32","9.10.11.0/24"],"type":"remote_ip"}}`))
+			Expect(ext).To(Not(BeNil()))
+			Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+			a.extensionConfig.MaxAllowedCIDRs = 2
+			Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+			ext.Spec.ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24","5.6.7.8/32","9.10.11.0/24","13.14.15.0/24"],"type":"remote_ip"}}`)}
+			Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("providerConfig.rule.cidrs")))
+		})
+
+		It("should keep reconciling a provider config exceeding the maximum number of CIDRs without recorded CIDRs", func() {
+			ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24","5.6.7.8/32","9.10.11.0/24"],"type":"remote_ip"}}`))
+			Expect(ext).To(Not(BeNil()))
+			// reconciled by a version of the extension not recording the CIDRs
+			ext.Status.LastOperation = &gardencorev1beta1.LastOperation{
+				Type:           gardencorev1beta1.LastOperationTypeReconcile,
+				State:          gardencorev1beta1.LastOperationStateSucceeded,
+				LastUpdateTime: metav1.Now(),
+			}
+
+			a.extensionConfig.MaxAllowedCIDRs = 2
+			Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+			extState, err := getExtensionState(ext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(extState.CIDRs).To(ConsistOf("1.2.3.0/24", "5.6.7.8/32", "9.10.11.0/24"))
+
+			ext.Spec.ProviderConfig = &runtime.RawExtension{Raw: []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24","5.6.7.8/32","9.10.11.0/24","13.14.15.0/24"],"type":"remote_ip"}}`)}
+			Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("providerConfig.rule.cidrs")))
+		})
+
+		It("should enforce the maximum number of CIDRs for new extensions", func() {
+			a.extensionConfig.MaxAllowedCIDRs = 2
+			ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24","5.6.7.8/32","9.10.11.0/24"],"type":"remote_ip"}}`))
+			Expect(ext).To(Not(BeNil()))
+
+			Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("providerConfig.rule.cidrs")))
+		})
+
+		It("should render the rules as shadow rules in shadow mode", func() {
+			ext := createNewExtension(shootNamespace1, []byte(`{"mode":"Shadow","rule":{"action":"ALLOW","cidrs":["1.2.3.0/24"],"type":"remote_ip"}}`))
+			Expect(ext).To(Not(BeNil()))
+
+			Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+			mr := &v1alpha1.ManagedResource{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+			Expect(secret.Data["seed"]).To(ContainSubstring("1.2.3.0"))
+			Expect(secret.Data["seed"]).To(ContainSubstring("shadow_rules_stat_prefix: acl_"))
+			Expect(secret.Data["seed"]).To(ContainSubstring("_api_shadow_"))
+			Expect(secret.Data["seed"]).To(ContainSubstring("_vpn_shadow_"))
+		})
+
+		Context("Hosts", func() {
+			var fakeResolver *resolver.Fake
+
+			BeforeEach(func() {
+				fakeResolver = resolver.NewFake(map[string][]string{
+					"office.example.com": {"11.12.13.14", "2001:db8::1"},
+				})
+				a.resolver = fakeResolver
+			})
+
+			getExtState := func(ext *extensionsv1alpha1.Extension) *ExtensionState {
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ext.Namespace, Name: ext.Name}, ext)).To(Succeed())
+				extState, err := getExtensionState(ext)
+				Expect(err).NotTo(HaveOccurred())
+				return extState
+			}
+
+			It("should add the addresses of the hosts and record them in the state", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24"],"hosts":["office.example.com"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				mr := &v1alpha1.ManagedResource{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+				Expect(secret.Data["seed"]).To(ContainSubstring("11.12.13.14"))
+				Expect(secret.Data["seed"]).To(ContainSubstring("2001:db8::1"))
+
+				Expect(getExtState(ext).Hosts).To(Equal(map[string][]string{
+					"office.example.com": {"11.12.13.14/32", "2001:db8::1/128"},
+				}))
+			})
+
+			It("should keep the previous addresses if a host cannot be resolved", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","hosts":["office.example.com"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				fakeResolver.SetHost("office.example.com")
+				ext = &extensionsv1alpha1.Extension{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: shootNamespace1, Name: "acl"}, ext)).To(Succeed())
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				Expect(getExtState(ext).Hosts).To(HaveKeyWithValue("office.example.com", []string{"11.12.13.14/32", "2001:db8::1/128"}))
+			})
+
+			It("should fail if a host has never been resolved", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","hosts":["partner.example.com"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("partner.example.com")))
+			})
+
+			It("should detect changed addresses", func() {
+				refresher := newHostsRefresher(k8sClient, a.decoder, fakeResolver, time.Minute, logger)
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","hosts":["office.example.com"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: shootNamespace1, Name: "acl"}, ext)).To(Succeed())
+
+				Expect(refresher.hostsChanged(ctx, ext)).To(BeFalse())
+
+				fakeResolver.SetHost("office.example.com", "11.12.13.15")
+				Expect(refresher.hostsChanged(ctx, ext)).To(BeTrue())
+
+				fakeResolver.SetHost("office.example.com")
+				changed, err := refresher.hostsChanged(ctx, ext)
+				Expect(err).To(HaveOccurred())
+				Expect(changed).To(BeFalse())
+			})
+		})
+
+		Context("GeoIP", func() {
+			It("should add the CIDRs of the countries and ASNs", func() {
+				a.geoIP = &geoIPReloader{}
+				a.geoIP.database.Store(geoip.NewDatabase(
+					map[string][]string{"DE": {"11.0.0.0/8"}},
+					map[uint32][]string{64496: {"2001:db8::/32"}},
+				))
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","countries":["DE"],"asns":[64496],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				mr := &v1alpha1.ManagedResource{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+				Expect(secret.Data["seed"]).To(ContainSubstring("11.0.0.0"))
+				Expect(secret.Data["seed"]).To(ContainSubstring("2001:db8::"))
+			})
+
+			It("should fail if no GeoIP database is configured", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","countries":["DE"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("no GeoIP database is configured")))
+			})
+
+			It("should fail for countries missing in the GeoIP database", func() {
+				a.geoIP = &geoIPReloader{}
+				a.geoIP.database.Store(geoip.NewDatabase(map[string][]string{"DE": {"11.0.0.0/8"}}, nil))
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","countries":["DE","FR"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("unknown countries: FR")))
+			})
+
+			It("should fail if the countries resolve to more CIDRs than allowed", func() {
+				a.extensionConfig.MaxRenderedCIDRs = 2
+				a.geoIP = &geoIPReloader{}
+				a.geoIP.database.Store(geoip.NewDatabase(map[string][]string{"DE": {"11.0.0.0/8", "12.0.0.0/8"}}, nil))
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["10.180.0.0/16"],"countries":["DE"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("resolve to 3 CIDRs, at most 2 are allowed")))
+			})
+
+			It("should reload the database when the files change", func() {
+				countriesFile := filepath.Join(GinkgoT().TempDir(), "countries.csv")
+				Expect(os.WriteFile(countriesFile, []byte("11.0.0.0,11.255.255.255,DE\n"), 0o600)).To(Succeed())
+
+				reloader, err := newGeoIPReloader(k8sClient, a.decoder, countriesFile, "", nil, logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reloader.Database().Countries([]string{"DE"})).To(HaveKeyWithValue("DE", []string{"11.0.0.0/8"}))
+				Expect(reloader.reload()).To(BeFalse())
+
+				Expect(os.WriteFile(countriesFile, []byte("12.0.0.0,12.0.255.255,DE\n"), 0o600)).To(Succeed())
+				Expect(reloader.reload()).To(BeTrue())
+				Expect(reloader.Database().Countries([]string{"DE"})).To(HaveKeyWithValue("DE", []string{"12.0.0.0/16"}))
+
+				Expect(os.WriteFile(countriesFile, []byte("invalid\ninvalid\n"), 0o600)).To(Succeed())
+				_, err = reloader.reload()
+				Expect(err).To(HaveOccurred())
+				Expect(reloader.Database().Countries([]string{"DE"})).To(HaveKeyWithValue("DE", []string{"12.0.0.0/16"}))
+			})
+
+			It("should only trigger the reconciliation on the leader", func() {
+				countriesFile := filepath.Join(GinkgoT().TempDir(), "countries.csv")
+				Expect(os.WriteFile(countriesFile, []byte("11.0.0.0,11.255.255.255,DE\n"), 0o600)).To(Succeed())
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","countries":["DE"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				elected := make(chan struct{})
+				reloader, err := newGeoIPReloader(k8sClient, a.decoder, countriesFile, "", elected, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				// nobody receives the events on other replicas, so the reload
+				// must not block
+				Expect(os.WriteFile(countriesFile, []byte("12.0.0.0,12.0.255.255,DE\n"), 0o600)).To(Succeed())
+				reloader.reloadAndEnqueue(ctx)
+				Expect(reloader.Database().Countries([]string{"DE"})).To(HaveKeyWithValue("DE", []string{"12.0.0.0/16"}))
+
+				close(elected)
+				Expect(os.WriteFile(countriesFile, []byte("13.0.0.0,13.0.0.255,DE\n"), 0o600)).To(Succeed())
+				reloadCtx, cancel := context.WithCancel(ctx)
+				done := make(chan struct{})
+				go func() {
+					defer close(done)
+					reloader.reloadAndEnqueue(reloadCtx)
+				}()
+				Eventually(reloader.events).Should(Receive(HaveField("Object.Name", ext.Name)))
+				cancel()
+				Eventually(done).Should(BeClosed())
+			})
+		})
+
+		Context("Schedules", func() {
+			const providerConfig = `{"rules":[
+				{"action":"ALLOW","cidrs":["10.180.0.0/16"],"type":"remote_ip"},
+				{"action":"ALLOW","cidrs":["11.12.0.0/16"],"type":"remote_ip","schedule":{"windows":[{"days":["Mon","Tue","Wed","Thu","Fri"],"start":"08:00","end":"18:00"}],"location":"Europe/Berlin"}}
+			]}`
+
+			getSeedSecret := func() *corev1.Secret {
+				mr := &v1alpha1.ManagedResource{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+				return secret
+			}
+
+			It("should render rules during their time windows", func() {
+				// Wednesday, 10:00 in Berlin
+				a.clock = testclock.NewFakePassiveClock(time.Date(2026, time.October, 14, 8, 0, 0, 0, time.UTC))
+				ext := createNewExtension(shootNamespace1, []byte(providerConfig))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				Expect(getSeedSecret().Data["seed"]).To(ContainSubstring("11.12.0.0"))
+				Expect(a.requeuer.timers).To(HaveKey(types.NamespacedName{Namespace: shootNamespace1, Name: "acl"}))
+			})
+
+			It("should not render rules outside of their time windows", func() {
+				// Friday, 19:00 in Berlin
+				a.clock = testclock.NewFakePassiveClock(time.Date(2026, time.October, 16, 17, 0, 0, 0, time.UTC))
+				ext := createNewExtension(shootNamespace1, []byte(providerConfig))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				seed := getSeedSecret().Data["seed"]
+				Expect(seed).To(ContainSubstring("10.180.0.0"))
+				Expect(seed).NotTo(ContainSubstring("11.12.0.0"))
+				Expect(a.requeuer.timers).To(HaveKey(types.NamespacedName{Namespace: shootNamespace1, Name: "acl"}))
+
+				Expect(a.Delete(ctx, logger, ext)).To(Succeed())
+				Expect(a.requeuer.timers).To(BeEmpty())
+			})
+
+			It("should update the rules after the controller restarted", func() {
+				// Friday, 17:00 in Berlin
+				a.clock = testclock.NewFakePassiveClock(time.Date(2026, time.October, 16, 15, 0, 0, 0, time.UTC))
+				ext := createNewExtension(shootNamespace1, []byte(providerConfig))
+				Expect(ext).To(Not(BeNil()))
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+				Expect(getSeedSecret().Data["seed"]).To(ContainSubstring("11.12.0.0"))
+
+				// the timer of the previous requeuer is lost, the new one
+				// triggers the reconciliation when starting
+				a.requeuer = newScheduleRequeuer(k8sClient, serializer.NewCodecFactory(clientScheme).UniversalDecoder(), logger)
+				a.clock = testclock.NewFakePassiveClock(time.Date(2026, time.October, 16, 17, 0, 0, 0, time.UTC))
+				Expect(receiveRequeuedExtensions(a.requeuer)).To(ContainElement(client.ObjectKeyFromObject(ext)))
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ext), ext)).To(Succeed())
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+				Expect(getSeedSecret().Data["seed"]).NotTo(ContainSubstring("11.12.0.0"))
+			})
+		})
+
+		Context("CIDR entries", func() {
+			const providerConfig = `{"rule":{"action":"ALLOW","cidrs":["10.180.0.0/16"],"type":"remote_ip","cidrEntries":[
+				{"cidr":"11.12.0.0/16","owner":"team-a","expiresAt":"2026-10-13T00:00:00Z"},
+				{"cidr":"12.13.0.0/16","owner":"team-b","description":"contractor","expiresAt":"2026-10-20T00:00:00Z"},
+				{"cidr":"13.14.0.0/16","expiresAt":"2027-10-20T00:00:00Z"}
+			]}}`
+
+			BeforeEach(func() {
+				a.clock = testclock.NewFakePassiveClock(time.Date(2026, time.October, 14, 8, 0, 0, 0, time.UTC))
+				a.extensionConfig.CIDRExpiryWarningHorizon = 14 * 24 * time.Hour
+			})
+
+			It("should only render unexpired entries and report the ones expiring soon", func() {
+				ext := createNewExtension(shootNamespace1, []byte(providerConfig))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				mr := &v1alpha1.ManagedResource{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+				Expect(secret.Data["seed"]).To(ContainSubstring("12.13.0.0"))
+				Expect(secret.Data["seed"]).To(ContainSubstring("13.14.0.0"))
+				Expect(secret.Data["seed"]).NotTo(ContainSubstring("11.12.0.0"))
+				Expect(a.requeuer.timers).To(HaveKey(types.NamespacedName{Namespace: shootNamespace1, Name: "acl"}))
+
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ext.Namespace, Name: ext.Name}, ext)).To(Succeed())
+				extStatus := &ExtensionStatus{}
+				Expect(json.Unmarshal(ext.Status.ProviderStatus.Raw, extStatus)).To(Succeed())
+				Expect(extStatus.ExpiringCIDREntries).To(HaveLen(1))
+				entry := extStatus.ExpiringCIDREntries[0]
+				Expect(entry.CIDR).To(Equal("12.13.0.0/16"))
+				Expect(entry.Owner).To(Equal("team-b"))
+				Expect(entry.Description).To(Equal("contractor"))
+				Expect(entry.ExpiresAt.Time).To(BeTemporally("==", time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)))
+			})
+
+			It("should not count expired entries towards the maximum number of CIDRs", func() {
+				a.extensionConfig.MaxAllowedCIDRs = 3
+				ext := createNewExtension(shootNamespace1, []byte(providerConfig))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+			})
+
+			It("should remove expired entries after the controller restarted", func() {
+				ext := createNewExtension(shootNamespace1, []byte(providerConfig))
+				Expect(ext).To(Not(BeNil()))
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				// the timer of the previous requeuer is lost, the new one
+				// triggers the reconciliation when starting
+				a.requeuer = newScheduleRequeuer(k8sClient, serializer.NewCodecFactory(clientScheme).UniversalDecoder(), logger)
+				a.clock = testclock.NewFakePassiveClock(time.Date(2026, time.October, 21, 8, 0, 0, 0, time.UTC))
+				Expect(receiveRequeuedExtensions(a.requeuer)).To(ContainElement(client.ObjectKeyFromObject(ext)))
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ext), ext)).To(Succeed())
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				mr := &v1alpha1.ManagedResource{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+				Expect(secret.Data["seed"]).To(ContainSubstring("13.14.0.0"))
+				Expect(secret.Data["seed"]).NotTo(ContainSubstring("12.13.0.0"))
+			})
+		})
+
+		Context("Break-glass access", func() {
+			var fakeClock *testclock.FakePassiveClock
+
+			BeforeEach(func() {
+				fakeClock = testclock.NewFakePassiveClock(time.Date(2026, time.October, 14, 8, 0, 0, 0, time.UTC))
+				a.clock = fakeClock
+			})
+
+			getSeed := func() string {
+				mr := &v1alpha1.ManagedResource{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+				return string(secret.Data["seed"])
+			}
+
+			It("should allow the CIDRs until the TTL expires", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["10.180.0.0/16"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+				ext.Annotations = map[string]string{AnnotationBreakGlassCIDRs: "203.0.113.7/32", AnnotationBreakGlassTTL: "4h"}
+				Expect(k8sClient.Update(ctx, ext)).To(Succeed())
+				recorder := a.recorder.(*events.FakeRecorder)
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				Expect(getSeed()).To(ContainSubstring("203.0.113.7"))
+				Expect(recorder.Events).To(Receive(Equal("Normal BreakGlassGranted Break-glass access for 203.0.113.7/32 granted until 2026-10-14T12:00:00Z")))
+				Expect(a.requeuer.timers).To(HaveKey(types.NamespacedName{Namespace: shootNamespace1, Name: "acl"}))
+
+				// later reconciliations do not renew the access
+				fakeClock.SetTime(fakeClock.Now().Add(4 * time.Hour))
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				Expect(getSeed()).NotTo(ContainSubstring("203.0.113.7"))
+				Expect(recorder.Events).To(Receive(Equal("Normal BreakGlassRevoked Break-glass access for 203.0.113.7/32 revoked, it expired")))
+				extState, err := getExtensionState(ext)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(extState.BreakGlass.Expired).To(BeTrue())
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+				Expect(recorder.Events).NotTo(Receive())
+			})
+
+			It("should not extend the access when a failed reconciliation is retried", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["10.180.0.0/16"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+				ext.Annotations = map[string]string{AnnotationBreakGlassCIDRs: "203.0.113.7/32", AnnotationBreakGlassTTL: "4h"}
+				Expect(k8sClient.Update(ctx, ext)).To(Succeed())
+				recorder := a.recorder.(*events.FakeRecorder)
+
+				// the reconciliation fails after the access was granted
+				gw := &istionetworkingv1beta1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "kube-apiserver", Namespace: shootNamespace1}}
+				Expect(k8sClient.Delete(ctx, gw)).To(Succeed())
+				Expect(a.Reconcile(ctx, logger, ext)).NotTo(Succeed())
+				Expect(recorder.Events).To(Receive(Equal("Normal BreakGlassGranted Break-glass access for 203.0.113.7/32 granted until 2026-10-14T12:00:00Z")))
+
+				createNewGateway("kube-apiserver", shootNamespace1, istioNamespace1Selector)
+				fakeClock.SetTime(fakeClock.Now().Add(time.Hour))
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ext), ext)).To(Succeed())
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				Expect(getSeed()).To(ContainSubstring("203.0.113.7"))
+				Expect(recorder.Events).NotTo(Receive())
+				extState, err := getExtensionState(ext)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(extState.BreakGlass.ExpiresAt.Time).To(BeTemporally("==", time.Date(2026, time.October, 14, 12, 0, 0, 0, time.UTC)))
+			})
+
+			It("should ignore invalid annotations", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["10.180.0.0/16"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+				ext.Annotations = map[string]string{AnnotationBreakGlassCIDRs: "203.0.113.7/32", AnnotationBreakGlassTTL: "720h"}
+				Expect(k8sClient.Update(ctx, ext)).To(Succeed())
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				Expect(getSeed()).NotTo(ContainSubstring("203.0.113.7"))
+				Expect(a.recorder.(*events.FakeRecorder).Events).To(Receive(HavePrefix("Warning BreakGlassInvalid")))
+
+				// the annotations are only reported again once they change
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+				Expect(a.recorder.(*events.FakeRecorder).Events).NotTo(Receive())
+
+				ext.Annotations[AnnotationBreakGlassTTL] = "0s"
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+				Expect(a.recorder.(*events.FakeRecorder).Events).To(Receive(HavePrefix("Warning BreakGlassInvalid")))
+			})
+
+			It("should revoke expired access after the controller restarted", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["10.180.0.0/16"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+				ext.Annotations = map[string]string{AnnotationBreakGlassCIDRs: "203.0.113.7/32", AnnotationBreakGlassTTL: "4h"}
+				Expect(k8sClient.Update(ctx, ext)).To(Succeed())
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+				Expect(getSeed()).To(ContainSubstring("203.0.113.7"))
+
+				// the timer of the previous requeuer is lost, the new one
+				// triggers the reconciliation when starting
+				a.requeuer = newScheduleRequeuer(k8sClient, serializer.NewCodecFactory(clientScheme).UniversalDecoder(), logger)
+				fakeClock.SetTime(fakeClock.Now().Add(4 * time.Hour))
+				Expect(receiveRequeuedExtensions(a.requeuer)).To(ContainElement(client.ObjectKeyFromObject(ext)))
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ext), ext)).To(Succeed())
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+				Expect(getSeed()).NotTo(ContainSubstring("203.0.113.7"))
+			})
+		})
+
+		Context("CIDRs from referenced resources", func() {
+			BeforeEach(func() {
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "ref-allow-list", Namespace: shootNamespace1},
+					Data:       map[string][]byte{"cidrs": []byte("11.12.13.0/24\n14.15.16.17/32")},
+				}
+				Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+			})
+
+			It("should add the CIDRs of the referenced resource", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24"],"cidrsFrom":{"resourceName":"acl-cidrs"},"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				mr := &v1alpha1.ManagedResource{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+				Expect(secret.Data["seed"]).To(ContainSubstring("1.2.3.0"))
+				Expect(secret.Data["seed"]).To(ContainSubstring("11.12.13.0"))
+				Expect(secret.Data["seed"]).To(ContainSubstring("14.15.16.17"))
+			})
+
+			It("should count the CIDRs of the referenced resource towards the maximum number of CIDRs", func() {
+				a.extensionConfig.MaxAllowedCIDRs = 2
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24"],"cidrsFrom":{"resourceName":"acl-cidrs"},"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("providerConfig.rule.cidrs")))
+			})
+
+			It("should fail if the resource is not listed in spec.resources", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrsFrom":{"resourceName":"other"},"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("resource other referenced by cidrsFrom is not listed")))
+			})
+
+			It("should fail if the resource does not contain the key", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrsFrom":{"resourceName":"acl-cidrs","key":"ipv6"},"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("key ipv6 not found")))
+			})
+		})
+
+		Context("CIDR sets", func() {
+			var cidrSetsConfigMap *corev1.ConfigMap
+
+			BeforeEach(func() {
+				cidrSetsConfigMap = &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "acl-cidr-sets", Namespace: "garden"},
+					Data: map[string]string{
+						"corporate-vpn": "11.12.13.0/24,14.15.16.17/32",
+						"ci-runners":    "21.22.23.0/24\n24.25.26.27/32",
+					},
+				}
+				Expect(k8sClient.Create(ctx, cidrSetsConfigMap)).To(Succeed())
+				DeferCleanup(func() {
+					Expect(k8sClient.Delete(ctx, cidrSetsConfigMap)).To(Succeed())
+				})
+
+				a.extensionConfig.CIDRSetsConfigMap = types.NamespacedName{Name: cidrSetsConfigMap.Name, Namespace: cidrSetsConfigMap.Namespace}
+			})
+
+			It("should add the CIDRs of the referenced CIDR sets", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24"],"cidrSets":["corporate-vpn"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+
+				mr := &v1alpha1.ManagedResource{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ResourceNameSeed, Namespace: shootNamespace1}, mr)).To(Succeed())
+				secret := &corev1.Secret{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mr.Spec.SecretRefs[0].Name, Namespace: shootNamespace1}, secret)).To(Succeed())
+				Expect(secret.Data["seed"]).To(ContainSubstring("1.2.3.0"))
+				Expect(secret.Data["seed"]).To(ContainSubstring("11.12.13.0"))
+				Expect(secret.Data["seed"]).To(ContainSubstring("14.15.16.17"))
+				Expect(secret.Data["seed"]).NotTo(ContainSubstring("21.22.23.0"))
+			})
+
+			It("should not count the CIDRs of the referenced CIDR sets towards the maximum number of CIDRs", func() {
+				a.extensionConfig.MaxAllowedCIDRs = 1
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrs":["1.2.3.0/24"],"cidrSets":["corporate-vpn","ci-runners"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(Succeed())
+			})
+
+			It("should fail if a referenced CIDR set does not exist", func() {
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrSets":["corporate-vpn","office"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("unknown CIDR sets: office")))
+			})
+
+			It("should fail if CIDR sets are referenced but not configured", func() {
+				a.extensionConfig.CIDRSetsConfigMap = types.NamespacedName{}
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrSets":["corporate-vpn"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("no CIDR sets are configured")))
+			})
+
+			It("should fail if a CIDR set contains an invalid CIDR", func() {
+				cidrSetsConfigMap.Data["corporate-vpn"] = "11.12.13.0/24,foo"
+				Expect(k8sClient.Update(ctx, cidrSetsConfigMap)).To(Succeed())
+				ext := createNewExtension(shootNamespace1, []byte(`{"rule":{"action":"ALLOW","cidrSets":["corporate-vpn"],"type":"remote_ip"}}`))
+				Expect(ext).To(Not(BeNil()))
+
+				Expect(a.Reconcile(ctx, logger, ext)).To(MatchError(ContainSubstring("CIDR set corporate-vpn: invalid CIDR")))
+			})
+		})
+
 		// gardener >= v1.89, including https://github.com/gardener/gardener/pull/9038
 		Context("ingress-nginx is exposed via istio", func() {
 			BeforeEach(func() {
@@ -140,8 +804,8 @@ var _ = Describe("actuator test", func() {
 			})
 
 			It("should create managed resource including acl-ingress-shoot EnvoyFilter object", func() {
-				extSpec := extensionspec.ExtensionSpec{
-					Rule: &envoyfilters.ACLRule{
+				extSpec := aclv1alpha1.ACLConfig{
+					Rule: &aclv1alpha1.ACLRule{
 						Cidrs:  []string{"1.2.3.4/24"},
 						Action: "ALLOW",
 						Type:   "remote_ip",
@@ -165,8 +829,8 @@ var _ = Describe("actuator test", func() {
 		// gardener < v1.89
 		Context("ingress-nginx is not exposed via istio", func() {
 			It("should create managed resource not including acl-ingress-shoot EnvoyFilter object", func() {
-				extSpec := extensionspec.ExtensionSpec{
-					Rule: &envoyfilters.ACLRule{
+				extSpec := aclv1alpha1.ACLConfig{
+					Rule: &aclv1alpha1.ACLRule{
 						Cidrs:  []string{"1.2.3.4/24"},
 						Action: "ALLOW",
 						Type:   "remote_ip",
@@ -203,8 +867,8 @@ var _ = Describe("actuator test", func() {
 
 		It("should not fail when the Gateway resource can't be found for an extension other than the one being reconciled (e.g. for hibernated clusters)", func() {
 			// arrange
-			extSpec1 := extensionspec.ExtensionSpec{
-				Rule: &envoyfilters.ACLRule{
+			extSpec1 := aclv1alpha1.ACLConfig{
+				Rule: &aclv1alpha1.ACLRule{
 					Cidrs:  []string{"1.2.3.4/24"},
 					Action: "ALLOW",
 					Type:   "remote_ip",
@@ -300,8 +964,8 @@ var _ = Describe("actuator test", func() {
 		It("should create ACLs including egressIPs of managedSeed", func() {
 			createShootInfo([]string{"1.1.1.1/32", "1.1.1.2/32"})
 
-			extSpec := extensionspec.ExtensionSpec{
-				Rule: &envoyfilters.ACLRule{
+			extSpec := aclv1alpha1.ACLConfig{
+				Rule: &aclv1alpha1.ACLRule{
 					Cidrs:  []string{"1.2.3.4/24"},
 					Action: "ALLOW",
 					Type:   "remote_ip",
@@ -328,8 +992,8 @@ var _ = Describe("actuator test", func() {
 		It("should modify the EnvoyFilter objects accordingly", func() {
 			By("1) creating the EnvoyFilter object correctly in the ORIGINAL namespace")
 			// arrange
-			extSpec := extensionspec.ExtensionSpec{
-				Rule: &envoyfilters.ACLRule{
+			extSpec := aclv1alpha1.ACLConfig{
+				Rule: &aclv1alpha1.ACLRule{
 					Cidrs:  []string{"1.2.3.4/24"},
 					Action: "ALLOW",
 					Type:   "remote_ip",
@@ -396,8 +1060,8 @@ var _ = Describe("actuator test", func() {
 	Describe("deletion of a hibernated cluster (no Gateway resource exists)", func() {
 		It("should properly clean up according ManagedResource", func() {
 			// arrange
-			extSpec := extensionspec.ExtensionSpec{
-				Rule: &envoyfilters.ACLRule{
+			extSpec := aclv1alpha1.ACLConfig{
+				Rule: &aclv1alpha1.ACLRule{
 					Cidrs:  []string{"1.2.3.4/24"},
 					Action: "ALLOW",
 					Type:   "remote_ip",
@@ -436,136 +1100,39 @@ var _ = Describe("actuator test", func() {
 	})
 })
 
-var _ = Describe("actuator unit test", func() {
-	var (
-		namespace       string
-		istioNamespace  string
-		maxallowedCIDRs int
-	)
-
-	BeforeEach(func() {
-		var istioNamespaceSelector map[string]string
-
-		namespace = createNewShootNamespace()
-		istioNamespace = createNewIstioNamespace()
-		istioNamespaceSelector = map[string]string{
-			"app":   "istio-ingressgateway",
-			"istio": istioNamespace,
+// receiveRequeuedExtensions returns the keys of all Extensions the given
+// requeuer triggers the reconciliation of when starting.
+func receiveRequeuedExtensions(requeuer *scheduleRequeuer) []client.ObjectKey {
+	enqueueCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		requeuer.enqueueExtensions(enqueueCtx)
+	}()
+
+	var keys []client.ObjectKey
+	for {
+		select {
+		case ev := <-requeuer.events:
+			keys = append(keys, client.ObjectKeyFromObject(ev.Object))
+		case <-done:
+			return keys
 		}
-		maxallowedCIDRs = 5
-
-		createNewGateway("kube-apiserver", namespace, istioNamespaceSelector)
-		createNewIstioDeployment(istioNamespace, istioNamespaceSelector)
-		createNewCluster(namespace)
-		createNewInfrastructure(namespace)
-	})
-
-	AfterEach(func() {
-		deleteNamespace(namespace)
-	})
-
-	Describe("ValidateExtensionSpec", func() {
-		When("there is an extension resource with one valid rule", func() {
-			It("Should not return an error", func() {
-				extSpec := &extensionspec.ExtensionSpec{}
-				addRuleToSpec(extSpec, "DENY", "source_ip", []string{"0.0.0.0/0"})
-
-				Expect(ValidateExtensionSpec(extSpec, maxallowedCIDRs)).To(Succeed())
-			})
-		})
-
-		When("there is an extension resource without rules", func() {
-			It("Should return an error", func() {
-				extSpec := &extensionspec.ExtensionSpec{}
-				Expect(ValidateExtensionSpec(extSpec, maxallowedCIDRs)).To(Equal(ErrSpecRule))
-			})
-		})
-
-		When("there is an extension resource with a rule with invalid rule type", func() {
-			It("Should return the correct error", func() {
-				extSpec := &extensionspec.ExtensionSpec{}
-				addRuleToSpec(extSpec, "DENY", "nonexistent", []string{"0.0.0.0/0"})
-
-				Expect(ValidateExtensionSpec(extSpec, maxallowedCIDRs)).To(Equal(ErrSpecType))
-			})
-		})
-
-		When("there is an extension resource with a rule with invalid rule action", func() {
-			It("Should return the correct error", func() {
-				extSpec := &extensionspec.ExtensionSpec{}
-				addRuleToSpec(extSpec, "NONEXISTENT", "remote_ip", []string{"0.0.0.0/0"})
-
-				Expect(ValidateExtensionSpec(extSpec, maxallowedCIDRs)).To(Equal(ErrSpecAction))
-			})
-		})
-
-		When("there is an extension resource with CIDR", func() {
-			It("Should return the correct error", func() {
-				extSpec := &extensionspec.ExtensionSpec{}
-				addRuleToSpec(extSpec, "DENY", "remote_ip", []string{"n0n3x1st3/nt"})
-
-				// we're not testing for a specific error, as they come from the
-				// net package here - no need for us to test these
-				Expect(ValidateExtensionSpec(extSpec, maxallowedCIDRs)).ToNot(Succeed())
-			})
-		})
-
-		When("there is an extension resource with a rule without CIDR", func() {
-			It("Should return the correct error", func() {
-				extSpec := &extensionspec.ExtensionSpec{}
-
-				extSpec.Rule = &envoyfilters.ACLRule{
-					Action: "DENY",
-					Type:   "remote_ip",
-				}
-
-				// we're not testing for a specific error, as they come from the
-				// net package here - no need for us to test these
-				Expect(ValidateExtensionSpec(extSpec, maxallowedCIDRs)).To(Equal(ErrSpecCIDR))
-			})
-		})
-
-		When("there is an extension resource with a rule with invalid CIDR", func() {
-			It("Should return the correct error", func() {
-				extSpec := &extensionspec.ExtensionSpec{}
-				addRuleToSpec(extSpec, "DENY", "remote_ip", []string{"n0n3x1st3/nt"})
-
-				// we're not testing for a specific error, as they come from the
-				// net package here - no need for us to test these
-				Expect(ValidateExtensionSpec(extSpec, maxallowedCIDRs)).ToNot(Succeed())
-			})
-		})
-		When("there is an extension resource with a rule with too many CIDRs", func() {
-			It("Should return the correct error", func() {
-				extSpec := &extensionspec.ExtensionSpec{}
-				var cidrs []string
-				for i := 0; i < maxallowedCIDRs+1; i++ {
-					cidrs = append(cidrs, fmt.Sprintf("10.%d.0.0/16", i))
-				}
-				addRuleToSpec(extSpec, "DENY", "remote_ip", cidrs)
-
-				// we're not testing for a specific error, as they come from the
-				// net package here - no need for us to test these
-				Expect(ValidateExtensionSpec(extSpec, maxallowedCIDRs)).To(Equal(ErrSpecTooManyCIDRs))
-			})
-		})
-	})
-})
+	}
+}
 
 func getNewActuator() *actuator {
 	return &actuator{
-		client: k8sClient,
-		config: cfg,
+		client:  k8sClient,
+		config:  cfg,
+		decoder: serializer.NewCodecFactory(clientScheme, serializer.EnableStrict).UniversalDecoder(),
 		extensionConfig: config.Config{
 			ChartPath: "../../charts",
 		},
-	}
-}
-
-func addRuleToSpec(extSpec *extensionspec.ExtensionSpec, action, ruleType string, cidr []string) {
-	extSpec.Rule = &envoyfilters.ACLRule{
-		Cidrs:  cidr,
-		Action: action,
-		Type:   ruleType,
+		resolver: resolver.NewFake(nil),
+		requeuer: newScheduleRequeuer(k8sClient, serializer.NewCodecFactory(clientScheme).UniversalDecoder(), logger),
+		clock:    clock.RealClock{},
+		recorder: events.NewFakeRecorder(100),
 	}
 }
@@ -17,27 +17,43 @@ package controller
 
 import (
 	"context"
+	"maps"
+	"net"
 	"slices"
 
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/extension"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
 	controllerconfig "github.com/stackitcloud/gardener-extension-acl/pkg/controller/config"
+	aclmetrics "github.com/stackitcloud/gardener-extension-acl/pkg/metrics"
 )
 
 const (
 	// Type is the type of Extension resource.
 	Type   = "acl"
 	suffix = "-extension-service"
+
+	// referencedResourcesName is the name of the ManagedResource the gardenlet
+	// uses to copy the resources listed in spec.resources of the shoot into the
+	// shoot namespace.
+	referencedResourcesName = "referenced-resources"
 )
 
 var (
@@ -65,8 +81,69 @@ func AddToManager(ctx context.Context, mgr manager.Manager) error {
 // AddToManagerWithOptions adds a controller with the given Options to the given manager.
 // The opts.Reconciler is being set with a newly instantiated actuator.
 func AddToManagerWithOptions(ctx context.Context, mgr manager.Manager, opts *AddOptions) error {
+	watchBuilder := slices.Concat(
+		watchInfrastructure(mgr),
+		watchCIDRSets(mgr, opts.ExtensionConfig.CIDRSetsConfigMap),
+		watchReferencedResources(mgr),
+		watchBreakGlass(mgr),
+	)
+
+	if interval := opts.ExtensionConfig.HostsResolutionInterval; interval > 0 {
+		refresher := newHostsRefresher(
+			mgr.GetClient(),
+			serializer.NewCodecFactory(mgr.GetScheme()).UniversalDecoder(),
+			net.DefaultResolver,
+			interval,
+			mgr.GetLogger().WithName("acl-hosts-refresher"),
+		)
+		if err := mgr.Add(refresher); err != nil {
+			return err
+		}
+		watchBuilder = append(watchBuilder, refresher.watch)
+	}
+
+	var geoIP *geoIPReloader
+	if cfg := opts.ExtensionConfig; cfg.GeoIPCountriesFile != "" || cfg.GeoIPASNsFile != "" {
+		var err error
+		geoIP, err = newGeoIPReloader(
+			mgr.GetClient(),
+			serializer.NewCodecFactory(mgr.GetScheme()).UniversalDecoder(),
+			cfg.GeoIPCountriesFile,
+			cfg.GeoIPASNsFile,
+			mgr.Elected(),
+			mgr.GetLogger().WithName("acl-geoip-reloader"),
+		)
+		if err != nil {
+			return err
+		}
+		if err := mgr.Add(geoIP); err != nil {
+			return err
+		}
+		watchBuilder = append(watchBuilder, geoIP.watch)
+	}
+
+	if port := opts.ExtensionConfig.EnvoyStatsPort; port > 0 {
+		exporter := aclmetrics.NewExporter(
+			aclmetrics.LeaderOnly(mgr.Elected(), aclmetrics.IngressGatewayTargets(mgr.GetAPIReader(), port)),
+			mgr.GetLogger().WithName("acl-denied-exporter"),
+		)
+		if err := metrics.Registry.Register(exporter); err != nil {
+			return err
+		}
+	}
+
+	requeuer := newScheduleRequeuer(
+		mgr.GetClient(),
+		serializer.NewCodecFactory(mgr.GetScheme()).UniversalDecoder(),
+		mgr.GetLogger().WithName("acl-schedule-requeuer"),
+	)
+	if err := mgr.Add(requeuer); err != nil {
+		return err
+	}
+	watchBuilder = append(watchBuilder, requeuer.watch)
+
 	return extension.Add(mgr, extension.AddArgs{
-		Actuator:          NewActuator(mgr, opts.ExtensionConfig),
+		Actuator:          NewActuator(mgr, opts.ExtensionConfig, geoIP, requeuer),
 		ControllerOptions: opts.ControllerOptions,
 		Name:              Type + suffix,
 		FinalizerSuffix:   Type + suffix,
@@ -74,7 +151,7 @@ func AddToManagerWithOptions(ctx context.Context, mgr manager.Manager, opts *Add
 		Predicates:        extension.DefaultPredicates(ctx, mgr, DefaultAddOptions.IgnoreOperationAnnotation),
 		Type:              Type,
 		ExtensionClasses:  []extensionsv1alpha1.ExtensionClass{opts.ExtensionClass},
-		WatchBuilder:      watchInfrastructure(mgr),
+		WatchBuilder:      watchBuilder,
 	})
 }
 
@@ -119,3 +196,152 @@ func watchInfrastructure(mgr manager.Manager) extensionscontroller.WatchBuilder
 		))
 	})
 }
+
+func cidrSetsPredicate(key types.NamespacedName) predicate.TypedFuncs[*corev1.ConfigMap] {
+	matches := func(configMap *corev1.ConfigMap) bool {
+		return configMap.Namespace == key.Namespace && configMap.Name == key.Name
+	}
+
+	return predicate.TypedFuncs[*corev1.ConfigMap]{
+		UpdateFunc: func(e event.TypedUpdateEvent[*corev1.ConfigMap]) bool {
+			// We want to reconcile if the CIDR sets changed
+			return matches(e.ObjectNew) && !maps.Equal(e.ObjectOld.Data, e.ObjectNew.Data)
+		},
+		CreateFunc: func(e event.TypedCreateEvent[*corev1.ConfigMap]) bool {
+			return matches(e.Object)
+		},
+		DeleteFunc: func(e event.TypedDeleteEvent[*corev1.ConfigMap]) bool {
+			return matches(e.Object)
+		},
+		GenericFunc: func(_ event.TypedGenericEvent[*corev1.ConfigMap]) bool {
+			return false
+		},
+	}
+}
+
+// watchCIDRSets watches for changes of the CIDR sets ConfigMap and triggers the
+// reconciliation of all Extensions referencing CIDR sets. It returns nil if no
+// CIDR sets are configured.
+func watchCIDRSets(mgr manager.Manager, key types.NamespacedName) extensionscontroller.WatchBuilder {
+	if key.Name == "" {
+		return nil
+	}
+
+	// the configs are only decoded to find the referenced CIDR sets, invalid
+	// configs are reported by the reconciliation
+	decoder := serializer.NewCodecFactory(mgr.GetScheme()).UniversalDecoder()
+
+	// Map CIDR sets changes to all Extensions referencing CIDR sets
+	mapFunc := func(ctx context.Context, _ *corev1.ConfigMap) []reconcile.Request {
+		extensions := &extensionsv1alpha1.ExtensionList{}
+		if err := mgr.GetClient().List(ctx, extensions); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list Extensions for CIDR sets change")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, ex := range extensions.Items {
+			if ex.Spec.Type != Type {
+				continue
+			}
+			extSpec, err := aclhelper.DecodeACLConfig(decoder, ex.Spec.ProviderConfig)
+			if err != nil || len(extSpec.AllCIDRSets()) == 0 {
+				continue
+			}
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&ex)})
+		}
+		return requests
+	}
+
+	return extensionscontroller.NewWatchBuilder(func(ctrl controller.Controller) error {
+		return ctrl.Watch(source.Kind(mgr.GetCache(), &corev1.ConfigMap{},
+			handler.TypedEnqueueRequestsFromMapFunc(mapFunc),
+			cidrSetsPredicate(key),
+		))
+	})
+}
+
+func referencedResourcesPredicate() predicate.TypedFuncs[*resourcesv1alpha1.ManagedResource] {
+	return predicate.TypedFuncs[*resourcesv1alpha1.ManagedResource]{
+		UpdateFunc: func(e event.TypedUpdateEvent[*resourcesv1alpha1.ManagedResource]) bool {
+			// We want to reconcile once the changed resources have been applied
+			return e.ObjectNew.Name == referencedResourcesName &&
+				e.ObjectOld.Status.ObservedGeneration != e.ObjectNew.Status.ObservedGeneration
+		},
+		CreateFunc: func(_ event.TypedCreateEvent[*resourcesv1alpha1.ManagedResource]) bool {
+			return false
+		},
+		DeleteFunc: func(_ event.TypedDeleteEvent[*resourcesv1alpha1.ManagedResource]) bool {
+			return false
+		},
+		GenericFunc: func(_ event.TypedGenericEvent[*resourcesv1alpha1.ManagedResource]) bool {
+			return false
+		},
+	}
+}
+
+// watchReferencedResources watches for changes of the resources referenced in
+// spec.resources of the shoots and triggers the reconciliation of the Extension
+// if it references any of them with cidrsFrom.
+func watchReferencedResources(mgr manager.Manager) extensionscontroller.WatchBuilder {
+	decoder := serializer.NewCodecFactory(mgr.GetScheme()).UniversalDecoder()
+
+	// Map changes of the referenced resources to the Extension
+	mapFunc := func(ctx context.Context, managedResource *resourcesv1alpha1.ManagedResource) []reconcile.Request {
+		ex := &extensionsv1alpha1.Extension{}
+		if err := mgr.GetClient().Get(ctx, types.NamespacedName{Name: Type, Namespace: managedResource.Namespace}, ex); err != nil {
+			if !apierrors.IsNotFound(err) {
+				log.FromContext(ctx).Error(err, "Failed to get Extension for referenced resources change", "namespace", managedResource.Namespace)
+			}
+			return nil
+		}
+
+		extSpec, err := aclhelper.DecodeACLConfig(decoder, ex.Spec.ProviderConfig)
+		if err != nil || len(extSpec.AllCIDRsFrom()) == 0 {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: client.ObjectKeyFromObject(ex)}}
+	}
+
+	// The referenced resources are copied during the shoot reconciliation,
+	// which reconciles the Extension as well. However, the copy is applied
+	// asynchronously, so the Extension might have read outdated resources.
+	return extensionscontroller.NewWatchBuilder(func(ctrl controller.Controller) error {
+		return ctrl.Watch(source.Kind(mgr.GetCache(), &resourcesv1alpha1.ManagedResource{},
+			handler.TypedEnqueueRequestsFromMapFunc(mapFunc),
+			referencedResourcesPredicate(),
+		))
+	})
+}
+
+func breakGlassPredicate() predicate.TypedFuncs[*extensionsv1alpha1.Extension] {
+	return predicate.TypedFuncs[*extensionsv1alpha1.Extension]{
+		UpdateFunc: func(e event.TypedUpdateEvent[*extensionsv1alpha1.Extension]) bool {
+			// We want to reconcile if the break-glass annotations changed
+			return e.ObjectNew.Spec.Type == Type &&
+				(e.ObjectOld.Annotations[AnnotationBreakGlassCIDRs] != e.ObjectNew.Annotations[AnnotationBreakGlassCIDRs] ||
+					e.ObjectOld.Annotations[AnnotationBreakGlassTTL] != e.ObjectNew.Annotations[AnnotationBreakGlassTTL])
+		},
+		CreateFunc: func(_ event.TypedCreateEvent[*extensionsv1alpha1.Extension]) bool {
+			return false
+		},
+		DeleteFunc: func(_ event.TypedDeleteEvent[*extensionsv1alpha1.Extension]) bool {
+			return false
+		},
+		GenericFunc: func(_ event.TypedGenericEvent[*extensionsv1alpha1.Extension]) bool {
+			return false
+		},
+	}
+}
+
+// watchBreakGlass watches for changes of the break-glass annotations of the
+// Extensions, which neither change their generation nor the shoot, and
+// triggers their reconciliation.
+func watchBreakGlass(mgr manager.Manager) extensionscontroller.WatchBuilder {
+	return extensionscontroller.NewWatchBuilder(func(ctrl controller.Controller) error {
+		return ctrl.Watch(source.Kind(mgr.GetCache(), &extensionsv1alpha1.Extension{},
+			&handler.TypedEnqueueRequestForObject[*extensionsv1alpha1.Extension]{},
+			breakGlassPredicate(),
+		))
+	})
+}
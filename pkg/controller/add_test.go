@@ -19,8 +19,12 @@ import (
 
 	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -88,3 +92,180 @@ var _ = Describe("infrastructurePredicate", func() {
 		})
 	})
 })
+
+var _ = Describe("cidrSetsPredicate", func() {
+	var (
+		p         predicate.TypedPredicate[*corev1.ConfigMap]
+		configMap *corev1.ConfigMap
+		other     *corev1.ConfigMap
+	)
+
+	BeforeEach(func() {
+		p = cidrSetsPredicate(types.NamespacedName{Namespace: "garden", Name: "acl-cidr-sets"})
+
+		configMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "garden", Name: "acl-cidr-sets"},
+			Data:       map[string]string{"corporate-vpn": "11.12.13.0/24"},
+		}
+		other = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "garden", Name: "other"},
+			Data:       map[string]string{"corporate-vpn": "11.12.13.0/24"},
+		}
+	})
+
+	Describe("#Create", func() {
+		It("should return true for the CIDR sets ConfigMap", func() {
+			Expect(p.Create(event.TypedCreateEvent[*corev1.ConfigMap]{Object: configMap})).To(BeTrue())
+		})
+
+		It("should return false for other ConfigMaps", func() {
+			Expect(p.Create(event.TypedCreateEvent[*corev1.ConfigMap]{Object: other})).To(BeFalse())
+		})
+	})
+
+	Describe("#Delete", func() {
+		It("should return true for the CIDR sets ConfigMap", func() {
+			Expect(p.Delete(event.TypedDeleteEvent[*corev1.ConfigMap]{Object: configMap})).To(BeTrue())
+		})
+
+		It("should return false for other ConfigMaps", func() {
+			Expect(p.Delete(event.TypedDeleteEvent[*corev1.ConfigMap]{Object: other})).To(BeFalse())
+		})
+	})
+
+	Describe("#Generic", func() {
+		It("should return false", func() {
+			Expect(p.Generic(event.TypedGenericEvent[*corev1.ConfigMap]{Object: configMap})).To(BeFalse())
+		})
+	})
+
+	Describe("#Update", func() {
+		It("should return true if the CIDR sets changed", func() {
+			newConfigMap := configMap.DeepCopy()
+			newConfigMap.Data["ci-runners"] = "21.22.23.0/24"
+
+			Expect(p.Update(event.TypedUpdateEvent[*corev1.ConfigMap]{ObjectNew: newConfigMap, ObjectOld: configMap})).To(BeTrue())
+		})
+
+		It("should return false if the CIDR sets have not changed", func() {
+			newConfigMap := configMap.DeepCopy()
+			newConfigMap.Labels = map[string]string{"foo": "bar"}
+
+			Expect(p.Update(event.TypedUpdateEvent[*corev1.ConfigMap]{ObjectNew: newConfigMap, ObjectOld: configMap})).To(BeFalse())
+		})
+
+		It("should return false for other ConfigMaps", func() {
+			newOther := other.DeepCopy()
+			newOther.Data["ci-runners"] = "21.22.23.0/24"
+
+			Expect(p.Update(event.TypedUpdateEvent[*corev1.ConfigMap]{ObjectNew: newOther, ObjectOld: other})).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("referencedResourcesPredicate", func() {
+	var (
+		p               predicate.TypedPredicate[*resourcesv1alpha1.ManagedResource]
+		managedResource *resourcesv1alpha1.ManagedResource
+	)
+
+	BeforeEach(func() {
+		p = referencedResourcesPredicate()
+
+		managedResource = &resourcesv1alpha1.ManagedResource{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shoot--foo--bar", Name: "referenced-resources", Generation: 2},
+			Status:     resourcesv1alpha1.ManagedResourceStatus{ObservedGeneration: 1},
+		}
+	})
+
+	Describe("#Create", func() {
+		It("should return false", func() {
+			Expect(p.Create(event.TypedCreateEvent[*resourcesv1alpha1.ManagedResource]{Object: managedResource})).To(BeFalse())
+		})
+	})
+
+	Describe("#Delete", func() {
+		It("should return false", func() {
+			Expect(p.Delete(event.TypedDeleteEvent[*resourcesv1alpha1.ManagedResource]{Object: managedResource})).To(BeFalse())
+		})
+	})
+
+	Describe("#Generic", func() {
+		It("should return false", func() {
+			Expect(p.Generic(event.TypedGenericEvent[*resourcesv1alpha1.ManagedResource]{Object: managedResource})).To(BeFalse())
+		})
+	})
+
+	Describe("#Update", func() {
+		It("should return true if the referenced resources have been applied", func() {
+			newManagedResource := managedResource.DeepCopy()
+			newManagedResource.Status.ObservedGeneration = 2
+
+			Expect(p.Update(event.TypedUpdateEvent[*resourcesv1alpha1.ManagedResource]{ObjectNew: newManagedResource, ObjectOld: managedResource})).To(BeTrue())
+		})
+
+		It("should return false if the observed generation has not changed", func() {
+			newManagedResource := managedResource.DeepCopy()
+			newManagedResource.Generation = 3
+
+			Expect(p.Update(event.TypedUpdateEvent[*resourcesv1alpha1.ManagedResource]{ObjectNew: newManagedResource, ObjectOld: managedResource})).To(BeFalse())
+		})
+
+		It("should return false for other ManagedResources", func() {
+			managedResource.Name = ResourceNameSeed
+			newManagedResource := managedResource.DeepCopy()
+			newManagedResource.Status.ObservedGeneration = 2
+
+			Expect(p.Update(event.TypedUpdateEvent[*resourcesv1alpha1.ManagedResource]{ObjectNew: newManagedResource, ObjectOld: managedResource})).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("breakGlassPredicate", func() {
+	var (
+		p         predicate.TypedPredicate[*extensionsv1alpha1.Extension]
+		extension *extensionsv1alpha1.Extension
+	)
+
+	BeforeEach(func() {
+		p = breakGlassPredicate()
+
+		extension = &extensionsv1alpha1.Extension{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shoot--foo--bar", Name: "acl"},
+			Spec:       extensionsv1alpha1.ExtensionSpec{DefaultSpec: extensionsv1alpha1.DefaultSpec{Type: Type}},
+		}
+	})
+
+	Describe("#Create", func() {
+		It("should return false", func() {
+			Expect(p.Create(event.TypedCreateEvent[*extensionsv1alpha1.Extension]{Object: extension})).To(BeFalse())
+		})
+	})
+
+	Describe("#Update", func() {
+		It("should return true if the break-glass annotations changed", func() {
+			newExtension := extension.DeepCopy()
+			newExtension.Annotations = map[string]string{AnnotationBreakGlassCIDRs: "203.0.113.7/32", AnnotationBreakGlassTTL: "4h"}
+			Expect(p.Update(event.TypedUpdateEvent[*extensionsv1alpha1.Extension]{ObjectNew: newExtension, ObjectOld: extension})).To(BeTrue())
+
+			newerExtension := newExtension.DeepCopy()
+			newerExtension.Annotations[AnnotationBreakGlassTTL] = "8h"
+			Expect(p.Update(event.TypedUpdateEvent[*extensionsv1alpha1.Extension]{ObjectNew: newerExtension, ObjectOld: newExtension})).To(BeTrue())
+		})
+
+		It("should return false if other annotations changed", func() {
+			newExtension := extension.DeepCopy()
+			newExtension.Annotations = map[string]string{"foo": "bar"}
+
+			Expect(p.Update(event.TypedUpdateEvent[*extensionsv1alpha1.Extension]{ObjectNew: newExtension, ObjectOld: extension})).To(BeFalse())
+		})
+
+		It("should return false for other Extensions", func() {
+			extension.Spec.Type = "other"
+			newExtension := extension.DeepCopy()
+			newExtension.Annotations = map[string]string{AnnotationBreakGlassCIDRs: "203.0.113.7/32", AnnotationBreakGlassTTL: "4h"}
+
+			Expect(p.Update(event.TypedUpdateEvent[*extensionsv1alpha1.Extension]{ObjectNew: newExtension, ObjectOld: extension})).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+const (
+	// AnnotationBreakGlassCIDRs is the annotation on the Extension granting the
+	// comma-separated CIDRs temporary access to all endpoints of the shoot, e.g.
+	// during incidents, without changing the shoot spec.
+	AnnotationBreakGlassCIDRs = "acl.extensions.gardener.cloud/break-glass-cidrs"
+	// AnnotationBreakGlassTTL is the annotation on the Extension containing the
+	// duration break-glass access is granted for, e.g. "4h".
+	AnnotationBreakGlassTTL = "acl.extensions.gardener.cloud/break-glass-ttl"
+
+	// maxBreakGlassTTL is the maximum duration break-glass access is granted for.
+	maxBreakGlassTTL = 24 * time.Hour
+)
+
+// BreakGlassState is the break-glass access granted with
+// AnnotationBreakGlassCIDRs.
+type BreakGlassState struct {
+	// CIDRs are the CIDRs granted access.
+	CIDRs []string `json:"cidrs"`
+	// TTL is the value of AnnotationBreakGlassTTL access was granted with.
+	TTL string `json:"ttl"`
+	// ExpiresAt is the time the access expires.
+	ExpiresAt metav1.Time `json:"expiresAt"`
+	// Expired is set once the access has expired and was revoked.
+	Expired bool `json:"expired,omitempty"`
+}
+
+// breakGlassEvent is an event about break-glass access, which is emitted once
+// the state recording the access is saved.
+type breakGlassEvent struct {
+	eventType string
+	reason    string
+	action    string
+	note      string
+}
+
+// reconcileBreakGlass updates the break-glass access recorded in the given
+// state according to the annotations of the Extension and returns an event
+// whenever access is granted or revoked. Access is granted once per value of
+// the annotations, so it is not renewed by later reconciliations; changing the
+// annotations grants it again. Invalid annotations are reported with an event
+// once per value and ignored. It returns the CIDRs granted access at now, the
+// time the access expires, or the zero time if no access is granted, and the
+// events to emit once the state is saved.
+func reconcileBreakGlass(ex *extensionsv1alpha1.Extension, state *ExtensionState, now time.Time) ([]string, time.Time, []breakGlassEvent) {
+	var events []breakGlassEvent
+
+	cidrs, ttl, err := parseBreakGlassAnnotations(ex.Annotations)
+	if err == nil {
+		state.InvalidBreakGlass = ""
+	}
+
+	switch {
+	case err != nil:
+		if invalid := ex.Annotations[AnnotationBreakGlassCIDRs] + ";" + ex.Annotations[AnnotationBreakGlassTTL]; state.InvalidBreakGlass != invalid {
+			state.InvalidBreakGlass = invalid
+			events = append(events, breakGlassEvent{corev1.EventTypeWarning, "BreakGlassInvalid", "GrantAccess",
+				fmt.Sprintf("Ignoring invalid break-glass annotations: %v", err)})
+		}
+	case cidrs == nil:
+		if state.BreakGlass != nil && !state.BreakGlass.Expired {
+			events = append(events, breakGlassEvent{corev1.EventTypeNormal, "BreakGlassRevoked", "RevokeAccess",
+				fmt.Sprintf("Break-glass access for %s revoked, the annotation was removed", strings.Join(state.BreakGlass.CIDRs, ", "))})
+		}
+		state.BreakGlass = nil
+	case state.BreakGlass == nil || !slices.Equal(state.BreakGlass.CIDRs, cidrs) || state.BreakGlass.TTL != ex.Annotations[AnnotationBreakGlassTTL]:
+		state.BreakGlass = &BreakGlassState{
+			CIDRs:     cidrs,
+			TTL:       ex.Annotations[AnnotationBreakGlassTTL],
+			ExpiresAt: metav1.NewTime(now.Add(ttl)),
+		}
+		events = append(events, breakGlassEvent{corev1.EventTypeNormal, "BreakGlassGranted", "GrantAccess",
+			fmt.Sprintf("Break-glass access for %s granted until %s", strings.Join(cidrs, ", "), state.BreakGlass.ExpiresAt.UTC().Format(time.RFC3339))})
+	}
+
+	if state.BreakGlass == nil || state.BreakGlass.Expired {
+		return nil, time.Time{}, events
+	}
+	if !state.BreakGlass.ExpiresAt.After(now) {
+		state.BreakGlass.Expired = true
+		events = append(events, breakGlassEvent{corev1.EventTypeNormal, "BreakGlassRevoked", "RevokeAccess",
+			fmt.Sprintf("Break-glass access for %s revoked, it expired", strings.Join(state.BreakGlass.CIDRs, ", "))})
+		return nil, time.Time{}, events
+	}
+	return state.BreakGlass.CIDRs, state.BreakGlass.ExpiresAt.Time, events
+}
+
+// parseBreakGlassAnnotations returns the normalized CIDRs and the TTL of the
+// break-glass annotations. The CIDRs are nil if AnnotationBreakGlassCIDRs is
+// not set.
+func parseBreakGlassAnnotations(annotations map[string]string) ([]string, time.Duration, error) {
+	value, ok := annotations[AnnotationBreakGlassCIDRs]
+	if !ok {
+		return nil, 0, nil
+	}
+
+	var cidrs []string
+	for cidr := range strings.SplitSeq(value, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, 0, fmt.Errorf("invalid CIDR %q in annotation %s", cidr, AnnotationBreakGlassCIDRs)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+
+	ttlValue := annotations[AnnotationBreakGlassTTL]
+	ttl, err := time.ParseDuration(ttlValue)
+	if err != nil || ttl <= 0 || ttl > maxBreakGlassTTL {
+		return nil, 0, fmt.Errorf("invalid value %q of annotation %s, must be a positive duration of at most %s", ttlValue, AnnotationBreakGlassTTL, maxBreakGlassTTL)
+	}
+
+	return aclhelper.NormalizeCIDRs(cidrs), ttl, nil
+}
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+// ExtensionStatus is the provider status of the Extension.
+type ExtensionStatus struct {
+	// ExpiringCIDREntries are the CIDR entries of the ACLConfig expiring within
+	// the CIDRExpiryWarningHorizon, ordered by their expiry.
+	ExpiringCIDREntries []ExpiringCIDREntry `json:"expiringCIDREntries,omitempty"`
+}
+
+// ExpiringCIDREntry is a CIDR entry expiring soon.
+type ExpiringCIDREntry struct {
+	CIDR        string      `json:"cidr"`
+	Description string      `json:"description,omitempty"`
+	Owner       string      `json:"owner,omitempty"`
+	ExpiresAt   metav1.Time `json:"expiresAt"`
+}
+
+// resolveCIDREntries adds the CIDRs of the CIDR entries of the given ACLConfig,
+// which have not expired at now, to their rules, see
+// aclhelper.ResolveCIDREntries. It returns the status reporting the entries
+// expiring within the CIDRExpiryWarningHorizon and the time the next entry
+// expires or enters the horizon, or the zero time if there is none.
+func (a *actuator) resolveCIDREntries(extSpec *acl.ACLConfig, now time.Time) (*ExtensionStatus, time.Time) {
+	var (
+		horizon = a.extensionConfig.CIDRExpiryWarningHorizon
+		status  = &ExtensionStatus{}
+		next    time.Time
+	)
+
+	for _, entry := range aclhelper.ExpiringCIDREntries(extSpec, now, horizon) {
+		status.ExpiringCIDREntries = append(status.ExpiringCIDREntries, ExpiringCIDREntry{
+			CIDR:        entry.CIDR,
+			Description: entry.Description,
+			Owner:       entry.Owner,
+			ExpiresAt:   *entry.ExpiresAt,
+		})
+	}
+	for _, rule := range extSpec.AllRules() {
+		for _, entry := range rule.CIDREntries {
+			if entry.ExpiresAt == nil {
+				continue
+			}
+			if warnAt := entry.ExpiresAt.Add(-horizon); warnAt.After(now) {
+				next = earliest(next, warnAt)
+			}
+		}
+	}
+
+	return status, earliest(next, aclhelper.ResolveCIDREntries(extSpec, now))
+}
+
+// earliest returns the earliest of the given times, ignoring zero times.
+func earliest(times ...time.Time) time.Time {
+	var result time.Time
+	for _, t := range times {
+		if !t.IsZero() && (result.IsZero() || t.Before(result)) {
+			result = t
+		}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+// resolveCIDRSets replaces the CIDR sets referenced by the rules of the given
+// ACLConfig with the CIDRs of the sets defined in the CIDRSetsConfigMap.
+func (a *actuator) resolveCIDRSets(ctx context.Context, extSpec *acl.ACLConfig) error {
+	if len(extSpec.AllCIDRSets()) == 0 {
+		return nil
+	}
+
+	key := a.extensionConfig.CIDRSetsConfigMap
+	if key.Name == "" {
+		return fmt.Errorf("the config references CIDR sets %s, but no CIDR sets are configured",
+			strings.Join(extSpec.AllCIDRSets(), ", "))
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := a.client.Get(ctx, key, configMap); err != nil {
+		return fmt.Errorf("error getting CIDR sets ConfigMap %s: %w", key, err)
+	}
+
+	cidrSets, err := parseCIDRSets(configMap.Data)
+	if err != nil {
+		return fmt.Errorf("invalid CIDR sets ConfigMap %s: %w", key, err)
+	}
+	return aclhelper.ResolveCIDRSets(extSpec, cidrSets)
+}
+
+// parseCIDRSets parses the data of the CIDR sets ConfigMap. Each key is the
+// name of a set, its value contains the CIDRs of the set, see
+// aclhelper.ParseCIDRList.
+func parseCIDRSets(data map[string]string) (map[string][]string, error) {
+	cidrSets := make(map[string][]string, len(data))
+	for name, value := range data {
+		cidrs, err := aclhelper.ParseCIDRList(value)
+		if err != nil {
+			return nil, fmt.Errorf("CIDR set %s: %w", name, err)
+		}
+		cidrSets[name] = cidrs
+	}
+	return cidrSets, nil
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	v1beta1helper "github.com/gardener/gardener/pkg/api/core/v1beta1/helper"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/helper"
+)
+
+// resolveCIDRsFrom replaces the cidrsFrom references of the rules of the given
+// ACLConfig with the CIDRs of the referenced resources. Gardener copies the
+// resources listed in spec.resources of the shoot into the shoot namespace,
+// prefixed with v1beta1constants.ReferencedResourcesPrefix.
+func (a *actuator) resolveCIDRsFrom(ctx context.Context, namespace string, cluster *extensionscontroller.Cluster, extSpec *acl.ACLConfig) error {
+	refs := extSpec.AllCIDRsFrom()
+	if len(refs) == 0 {
+		return nil
+	}
+
+	cidrsFrom := make(map[acl.CIDRsFromReference][]string, len(refs))
+	for _, ref := range refs {
+		resource := v1beta1helper.GetResourceByName(cluster.Shoot.Spec.Resources, ref.ResourceName)
+		if resource == nil {
+			return fmt.Errorf("resource %s referenced by cidrsFrom is not listed in spec.resources of the shoot", ref.ResourceName)
+		}
+
+		cidrs, err := helper.GetReferencedCIDRs(ctx, a.client, resource.ResourceRef.Kind, namespace,
+			v1beta1constants.ReferencedResourcesPrefix+resource.ResourceRef.Name, ref.Key)
+		if err != nil {
+			return fmt.Errorf("error reading CIDRs of resource %s: %w", ref.ResourceName, err)
+		}
+		cidrsFrom[ref] = cidrs
+	}
+	return aclhelper.ResolveCIDRsFrom(extSpec, cidrsFrom)
+}
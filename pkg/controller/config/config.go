@@ -15,6 +15,12 @@
 
 package config
 
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
 // Config contains configuration for the extension service.
 type Config struct {
 	// TODO define options
@@ -23,4 +29,27 @@ type Config struct {
 	AdditionalAllowedCIDRs []string
 	// MaxAllowedCIDRs is the maximum number of allowed CIDRs per cluster
 	MaxAllowedCIDRs int
+	// MaxRenderedCIDRs is the maximum number of CIDRs rendered per endpoint,
+	// i.e. after resolving CIDR sets, hosts, countries and ASNs. Zero means no
+	// limit.
+	MaxRenderedCIDRs int
+	// CIDRSetsConfigMap is the ConfigMap containing the named CIDR sets rules
+	// can reference. It is not set if no CIDR sets are configured.
+	CIDRSetsConfigMap types.NamespacedName
+	// HostsResolutionInterval is the interval in which the hosts of all
+	// extensions are resolved again. Zero disables the periodic resolution.
+	HostsResolutionInterval time.Duration
+	// GeoIPCountriesFile is the MMDB or CSV file mapping addresses to country
+	// codes. It is not set if rules cannot reference countries.
+	GeoIPCountriesFile string
+	// GeoIPASNsFile is the MMDB or CSV file mapping addresses to autonomous
+	// system numbers. It is not set if rules cannot reference ASNs.
+	GeoIPASNsFile string
+	// CIDRExpiryWarningHorizon is the duration before their expiry CIDR entries
+	// are reported in the status of the extension.
+	CIDRExpiryWarningHorizon time.Duration
+	// EnvoyStatsPort is the port the istio-ingressgateway pods serve the stats
+	// of Envoy in the Prometheus format on, which are scraped for the denied
+	// connections. Zero disables the acl_denied_total metric.
+	EnvoyStatsPort int
 }
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/geoip"
+)
+
+// geoIPReloadInterval is the interval in which the GeoIP database files are
+// checked for changes.
+const geoIPReloadInterval = time.Minute
+
+// resolveGeoIP replaces the countries and ASNs of the rules of the given
+// ACLConfig with their CIDRs from the GeoIP database.
+func (a *actuator) resolveGeoIP(extSpec *acl.ACLConfig) error {
+	countries, asns := extSpec.AllCountries(), extSpec.AllASNs()
+	if len(countries) == 0 && len(asns) == 0 {
+		return nil
+	}
+	if a.geoIP == nil {
+		return errors.New("countries and ASNs are not supported, no GeoIP database is configured")
+	}
+
+	db := a.geoIP.Database()
+	countryCIDRs, err := db.Countries(countries)
+	if err != nil {
+		return err
+	}
+	asnCIDRs, err := db.ASNs(asns)
+	if err != nil {
+		return err
+	}
+	return aclhelper.ResolveGeoIP(extSpec, countryCIDRs, asnCIDRs)
+}
+
+// geoIPReloader loads the GeoIP database from the configured files, reloads it
+// when the files change and triggers the reconciliation of all Extensions
+// referencing countries or ASNs afterwards.
+//
+// The database is reloaded on all replicas, so a replica taking over the
+// leadership starts with an up-to-date database, but only the leader triggers
+// the reconciliations, as the controller only runs there.
+type geoIPReloader struct {
+	client        client.Reader
+	decoder       runtime.Decoder
+	countriesFile string
+	asnsFile      string
+	log           logr.Logger
+	elected       <-chan struct{}
+	events        chan event.TypedGenericEvent[*extensionsv1alpha1.Extension]
+
+	database atomic.Pointer[geoip.Database]
+	stamp    string
+}
+
+// newGeoIPReloader returns a geoIPReloader with the database loaded from the
+// given files. It fails if the files cannot be loaded. elected is closed once
+// the replica is elected as leader, see manager.Manager.Elected.
+func newGeoIPReloader(
+	c client.Reader, decoder runtime.Decoder, countriesFile, asnsFile string, elected <-chan struct{}, log logr.Logger,
+) (*geoIPReloader, error) {
+	r := &geoIPReloader{
+		client:        c,
+		decoder:       decoder,
+		countriesFile: countriesFile,
+		asnsFile:      asnsFile,
+		log:           log,
+		elected:       elected,
+		events:        make(chan event.TypedGenericEvent[*extensionsv1alpha1.Extension]),
+	}
+	if _, err := r.reload(); err != nil {
+		return nil, fmt.Errorf("failed to load GeoIP database: %w", err)
+	}
+	return r, nil
+}
+
+// Database returns the last successfully loaded database.
+func (r *geoIPReloader) Database() *geoip.Database {
+	return r.database.Load()
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. The reloader
+// runs on all replicas.
+func (r *geoIPReloader) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable.
+func (r *geoIPReloader) Start(ctx context.Context) error {
+	ticker := time.NewTicker(geoIPReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.reloadAndEnqueue(ctx)
+		}
+	}
+}
+
+// reloadAndEnqueue reloads the database and, if it changed and the replica is
+// the leader, triggers the reconciliation of the Extensions referencing
+// countries or ASNs.
+func (r *geoIPReloader) reloadAndEnqueue(ctx context.Context) {
+	reloaded, err := r.reload()
+	if err != nil {
+		r.log.Error(err, "Failed to reload GeoIP database, keeping the previous one")
+		return
+	}
+	if !reloaded {
+		return
+	}
+
+	select {
+	case <-r.elected:
+	default:
+		// the new leader reconciles all Extensions anyway
+		r.log.Info("Reloaded GeoIP database")
+		return
+	}
+	r.log.Info("Reloaded GeoIP database, triggering reconciliation")
+	r.enqueueExtensions(ctx)
+}
+
+// reload loads the database again if the modification time or size of the
+// files changed since the last successful load.
+func (r *geoIPReloader) reload() (bool, error) {
+	stamp, err := r.fileStamp()
+	if err != nil {
+		return false, err
+	}
+	if stamp == r.stamp {
+		return false, nil
+	}
+
+	db, err := geoip.Load(r.countriesFile, r.asnsFile)
+	if err != nil {
+		return false, err
+	}
+	r.database.Store(db)
+	r.stamp = stamp
+	return true, nil
+}
+
+func (r *geoIPReloader) fileStamp() (string, error) {
+	var stamp string
+	for _, name := range []string{r.countriesFile, r.asnsFile} {
+		if name == "" {
+			continue
+		}
+		// os.Stat follows symlinks, so files mounted from a ConfigMap or Secret
+		// are detected as changed when the volume is updated
+		info, err := os.Stat(name)
+		if err != nil {
+			return "", err
+		}
+		stamp += fmt.Sprintf("%s:%d:%d;", name, info.ModTime().UnixNano(), info.Size())
+	}
+	return stamp, nil
+}
+
+// enqueueExtensions sends an event for every Extension referencing countries
+// or ASNs.
+func (r *geoIPReloader) enqueueExtensions(ctx context.Context) {
+	extensions := &extensionsv1alpha1.ExtensionList{}
+	if err := r.client.List(ctx, extensions); err != nil {
+		r.log.Error(err, "Failed to list Extensions for GeoIP database change")
+		return
+	}
+
+	for _, ex := range extensions.Items {
+		if ex.Spec.Type != Type || ex.DeletionTimestamp != nil {
+			continue
+		}
+		extSpec, err := aclhelper.DecodeACLConfig(r.decoder, ex.Spec.ProviderConfig)
+		if err != nil || (len(extSpec.AllCountries()) == 0 && len(extSpec.AllASNs()) == 0) {
+			continue
+		}
+
+		select {
+		case r.events <- event.TypedGenericEvent[*extensionsv1alpha1.Extension]{Object: ex.DeepCopy()}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watch triggers the reconciliation of the Extensions sent by the reloader.
+func (r *geoIPReloader) watch(ctrl controller.Controller) error {
+	return ctrl.Watch(source.Channel(r.events, &handler.TypedEnqueueRequestForObject[*extensionsv1alpha1.Extension]{}))
+}
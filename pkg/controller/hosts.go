@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+	"github.com/stackitcloud/gardener-extension-acl/pkg/resolver"
+)
+
+// resolveHosts replaces the hosts of the rules of the given ACLConfig with the
+// CIDRs of their addresses and records them in the given ExtensionState. If a
+// host cannot be resolved, the CIDRs recorded by an earlier reconciliation are
+// used, so a failed lookup never removes a host from the allow-list.
+func (a *actuator) resolveHosts(ctx context.Context, log logr.Logger, extSpec *acl.ACLConfig, extState *ExtensionState) error {
+	hosts := extSpec.AllHosts()
+	if len(hosts) == 0 {
+		extState.Hosts = nil
+		return nil
+	}
+
+	hostCIDRs, err := resolver.LookupHosts(ctx, a.resolver, hosts)
+	if err != nil {
+		var unresolved []string
+		for _, host := range hosts {
+			if _, ok := hostCIDRs[host]; ok {
+				continue
+			}
+			if cidrs, ok := extState.Hosts[host]; ok {
+				hostCIDRs[host] = cidrs
+				continue
+			}
+			unresolved = append(unresolved, host)
+		}
+		if len(unresolved) > 0 {
+			return fmt.Errorf("failed to resolve hosts without previously resolved addresses: %w", err)
+		}
+		log.Error(err, "Failed to resolve hosts, using previously resolved addresses")
+	}
+
+	extState.Hosts = hostCIDRs
+	return aclhelper.ResolveHosts(extSpec, hostCIDRs)
+}
+
+// hostsRefresher periodically resolves the hosts of all Extensions again and
+// triggers the reconciliation of the Extensions whose addresses changed.
+type hostsRefresher struct {
+	client   client.Reader
+	decoder  runtime.Decoder
+	resolver resolver.Resolver
+	interval time.Duration
+	log      logr.Logger
+	events   chan event.TypedGenericEvent[*extensionsv1alpha1.Extension]
+}
+
+func newHostsRefresher(c client.Reader, decoder runtime.Decoder, r resolver.Resolver, interval time.Duration, log logr.Logger) *hostsRefresher {
+	return &hostsRefresher{
+		client:   c,
+		decoder:  decoder,
+		resolver: r,
+		interval: interval,
+		log:      log,
+		events:   make(chan event.TypedGenericEvent[*extensionsv1alpha1.Extension]),
+	}
+}
+
+// Start implements manager.Runnable. As it does not implement
+// manager.LeaderElectionRunnable, it only runs on the leader.
+func (r *hostsRefresher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.refresh(ctx)
+		}
+	}
+}
+
+// refresh resolves the hosts of all Extensions and sends an event for every
+// Extension whose resolved addresses differ from the ones of its last
+// reconciliation. Hosts which cannot be resolved are ignored.
+func (r *hostsRefresher) refresh(ctx context.Context) {
+	extensions := &extensionsv1alpha1.ExtensionList{}
+	if err := r.client.List(ctx, extensions); err != nil {
+		r.log.Error(err, "Failed to list Extensions for resolving hosts")
+		return
+	}
+
+	for _, ex := range extensions.Items {
+		if ex.Spec.Type != Type || ex.DeletionTimestamp != nil {
+			continue
+		}
+		changed, err := r.hostsChanged(ctx, &ex)
+		if err != nil {
+			r.log.Error(err, "Failed to check hosts of Extension", "extension", client.ObjectKeyFromObject(&ex))
+		}
+		if !changed {
+			continue
+		}
+
+		r.log.Info("Addresses of hosts changed, triggering reconciliation", "extension", client.ObjectKeyFromObject(&ex))
+		select {
+		case r.events <- event.TypedGenericEvent[*extensionsv1alpha1.Extension]{Object: ex.DeepCopy()}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// hostsChanged checks whether the addresses of any resolvable host of the given
+// Extension differ from the ones recorded in its ExtensionState.
+func (r *hostsRefresher) hostsChanged(ctx context.Context, ex *extensionsv1alpha1.Extension) (bool, error) {
+	extSpec, err := aclhelper.DecodeACLConfig(r.decoder, ex.Spec.ProviderConfig)
+	if err != nil {
+		return false, err
+	}
+	hosts := extSpec.AllHosts()
+	if len(hosts) == 0 {
+		return false, nil
+	}
+	extState, err := getExtensionState(ex)
+	if err != nil {
+		return false, err
+	}
+
+	// failed lookups are ignored, the reconciliation keeps the previous
+	// addresses for them anyway
+	hostCIDRs, err := resolver.LookupHosts(ctx, r.resolver, hosts)
+	for _, host := range slices.Sorted(maps.Keys(hostCIDRs)) {
+		if !slices.Equal(hostCIDRs[host], extState.Hosts[host]) {
+			return true, err
+		}
+	}
+	return false, err
+}
+
+// watch triggers the reconciliation of the Extensions sent by the refresher.
+func (r *hostsRefresher) watch(ctrl controller.Controller) error {
+	return ctrl.Watch(source.Channel(r.events, &handler.TypedEnqueueRequestForObject[*extensionsv1alpha1.Extension]{}))
+}
@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+// scheduleRequeuer triggers the reconciliation of Extensions at the next start
+// or end of the schedules of their rules, as the Extensions are not resynced
+// periodically.
+//
+// The requests are only kept in memory, so they are lost when the controller
+// restarts or the leadership moves. Therefore, the requeuer triggers the
+// reconciliation of all Extensions depending on the time once it is started.
+type scheduleRequeuer struct {
+	client  client.Reader
+	decoder runtime.Decoder
+	log     logr.Logger
+
+	mu      sync.Mutex
+	timers  map[types.NamespacedName]*time.Timer
+	events  chan event.TypedGenericEvent[*extensionsv1alpha1.Extension]
+	stopped chan struct{}
+}
+
+func newScheduleRequeuer(c client.Reader, decoder runtime.Decoder, log logr.Logger) *scheduleRequeuer {
+	return &scheduleRequeuer{
+		client:  c,
+		decoder: decoder,
+		log:     log,
+		timers:  map[types.NamespacedName]*time.Timer{},
+		events:  make(chan event.TypedGenericEvent[*extensionsv1alpha1.Extension]),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start implements manager.Runnable. As it does not implement
+// manager.LeaderElectionRunnable, it only runs on the leader. It triggers the
+// reconciliation of all Extensions depending on the time and afterwards waits
+// until the context is done.
+func (r *scheduleRequeuer) Start(ctx context.Context) error {
+	defer close(r.stopped)
+
+	r.enqueueExtensions(ctx)
+	<-ctx.Done()
+	return nil
+}
+
+// enqueueExtensions sends an event for every Extension depending on the time,
+// see dependsOnTime.
+func (r *scheduleRequeuer) enqueueExtensions(ctx context.Context) {
+	extensions := &extensionsv1alpha1.ExtensionList{}
+	if err := r.client.List(ctx, extensions); err != nil {
+		r.log.Error(err, "Failed to list Extensions depending on the time")
+		return
+	}
+
+	for _, ex := range extensions.Items {
+		if ex.Spec.Type != Type || ex.DeletionTimestamp != nil || !r.dependsOnTime(&ex) {
+			continue
+		}
+
+		select {
+		case r.events <- event.TypedGenericEvent[*extensionsv1alpha1.Extension]{Object: ex.DeepCopy()}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// dependsOnTime checks whether the rendered rules of the given Extension change
+// over time, i.e. whether it has unexpired break-glass access, rules with a
+// schedule or CIDR entries with an expiry.
+func (r *scheduleRequeuer) dependsOnTime(ex *extensionsv1alpha1.Extension) bool {
+	extState, err := getExtensionState(ex)
+	if err != nil {
+		// the reconciliation reports the error
+		return true
+	}
+	if extState.BreakGlass != nil && !extState.BreakGlass.Expired {
+		return true
+	}
+
+	extSpec, err := aclhelper.DecodeACLConfig(r.decoder, ex.Spec.ProviderConfig)
+	if err != nil {
+		return true
+	}
+	for _, rule := range extSpec.AllRules() {
+		if rule.Schedule != nil {
+			return true
+		}
+		if slices.ContainsFunc(rule.CIDREntries, func(entry acl.CIDREntry) bool { return entry.ExpiresAt != nil }) {
+			return true
+		}
+	}
+	return false
+}
+
+// requeueAfter triggers the reconciliation of the given Extension after the given
+// duration, replacing any earlier request. A duration less than zero cancels
+// the request.
+func (r *scheduleRequeuer) requeueAfter(key types.NamespacedName, after time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if timer, ok := r.timers[key]; ok {
+		timer.Stop()
+		delete(r.timers, key)
+	}
+	if after < 0 {
+		return
+	}
+
+	r.timers[key] = time.AfterFunc(after, func() {
+		r.mu.Lock()
+		delete(r.timers, key)
+		r.mu.Unlock()
+
+		// don't block if the requeuer is stopped, the next leader triggers the
+		// reconciliation when starting
+		select {
+		case r.events <- event.TypedGenericEvent[*extensionsv1alpha1.Extension]{Object: &extensionsv1alpha1.Extension{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		}}:
+		case <-r.stopped:
+		}
+	})
+}
+
+// watch triggers the reconciliation of the Extensions sent by the requeuer.
+func (r *scheduleRequeuer) watch(ctrl controller.Controller) error {
+	return ctrl.Watch(source.Channel(r.events, &handler.TypedEnqueueRequestForObject[*extensionsv1alpha1.Extension]{}))
+}
+
+// getMaintenanceWindow returns the maintenance time window of the shoot of the
+// given cluster or nil if it has none.
+func getMaintenanceWindow(cluster *extensionscontroller.Cluster) *aclhelper.MaintenanceWindow {
+	if cluster.Shoot.Spec.Maintenance == nil || cluster.Shoot.Spec.Maintenance.TimeWindow == nil {
+		return nil
+	}
+	return &aclhelper.MaintenanceWindow{
+		Begin: cluster.Shoot.Spec.Maintenance.TimeWindow.Begin,
+		End:   cluster.Shoot.Spec.Maintenance.TimeWindow.End,
+	}
+}
@@ -19,6 +19,7 @@ import (
 	istionetworkingv1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	istionetworkingv1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -30,6 +31,8 @@ import (
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	aclinstall "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/install"
 )
 
 var cfg *rest.Config
@@ -65,6 +68,7 @@ var _ = BeforeSuite(func() {
 	Expect(apiextensions.AddToScheme(clientScheme)).To(Succeed())
 	Expect(istionetworkingv1beta1.AddToScheme(clientScheme)).To(Succeed())
 	Expect(istionetworkingv1alpha3.AddToScheme(clientScheme)).To(Succeed())
+	Expect(aclinstall.AddToScheme(clientScheme)).To(Succeed())
 
 	cfg, err = testEnv.Start()
 	Expect(err).ToNot(HaveOccurred())
@@ -259,6 +263,10 @@ func createNewCluster(shootNamespace string) {
 							Nodes: nil,
 							Pods:  nil,
 						},
+						Resources: []gardencorev1beta1.NamedResourceReference{{
+							Name:        "acl-cidrs",
+							ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "allow-list", APIVersion: "v1"},
+						}},
 					},
 					Status: gardencorev1beta1.ShootStatus{
 						TechnicalID: shootNamespace,
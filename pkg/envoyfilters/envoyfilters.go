@@ -3,11 +3,15 @@ package envoyfilters
 import (
 	"errors"
 	"fmt"
-	"net"
+	"maps"
+	"net/netip"
+	"slices"
 	"strings"
 
 	"github.com/gardener/gardener/extensions/pkg/controller"
 
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
 	"github.com/stackitcloud/gardener-extension-acl/pkg/helper"
 )
 
@@ -16,23 +20,46 @@ var (
 	ErrNoHostsGiven = errors.New("no hosts were given, at least one host is needed")
 )
 
-// ACLRule contains a single ACL rule, consisting of a list of CIDRs, an action
-// and a rule type.
-type ACLRule struct {
-	// Cidrs contains a list of CIDR blocks to which the ACL rule applies
-	Cidrs []string `json:"cidrs"`
-	// Action defines if the rule is a DENY or an ALLOW rule
-	Action string `json:"action"`
-	// Type can either be "source_ip", "direct_remote_ip" or "remote_ip"
-	Type string `json:"type"`
+// IngressComponentPrefixes maps the names of the observability components
+// exposed via the seed ingress domain to the prefix of their host names, e.g.
+// Plutono is exposed as `gu-<shortID>.<ingressDomain>`.
+var IngressComponentPrefixes = map[string]string{
+	acl.IngressComponentPlutono:      "gu",
+	acl.IngressComponentPrometheus:   "p",
+	acl.IngressComponentAlertmanager: "au",
+	acl.IngressComponentVali:         "v",
+}
+
+// The endpoints of a shoot as named in the prefix of the stats of their RBAC
+// filters, see statPrefix.
+const (
+	statEndpointAPI       = "api"
+	statEndpointVPN       = "vpn"
+	statEndpointHTTPProxy = "httpproxy"
+	statEndpointIngress   = "ingress"
+)
+
+// defaultStatPrefix is the prefix of the stats of RBAC filters not belonging to
+// a single shoot.
+const defaultStatPrefix = "envoyrbac"
+
+// statPrefix returns the prefix of the stats of the RBAC filter of the given
+// shoot and endpoint, e.g. `acl_<shortID>_api_`, so the denied connections can
+// be attributed to them.
+func statPrefix(shortShootID, endpoint string) string {
+	return "acl_" + shortShootID + "_" + endpoint + "_"
 }
 
 // BuildAPIEnvoyFilterSpecForHelmChart assembles EnvoyFilter patches for API server
-// networking for every rule in the extension spec.
+// networking for every rule in the extension spec. If shadow is set, the rules
+// are not enforced, see policiesToTypedConfig.
 func BuildAPIEnvoyFilterSpecForHelmChart(
-	rule *ACLRule, hosts, alwaysAllowedCIDRs []string, istioLabels map[string]string,
+	cluster *controller.Cluster, rules []acl.ACLRule, hosts, alwaysAllowedCIDRs []string, istioLabels map[string]string,
+	shadow bool,
 ) (map[string]interface{}, error) {
-	apiConfigPatch, err := CreateAPIConfigPatchFromRule(rule, hosts, alwaysAllowedCIDRs)
+	apiConfigPatch, err := CreateAPIConfigPatchFromRule(
+		rules, hosts, alwaysAllowedCIDRs, statPrefix(helper.ComputeShortShootID(cluster.Shoot), statEndpointAPI), shadow,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -48,9 +75,12 @@ func BuildAPIEnvoyFilterSpecForHelmChart(
 }
 
 // BuildIngressEnvoyFilterSpecForHelmChart assembles EnvoyFilter patches for
-// endpoints using the seed ingress domain.
+// endpoints using the seed ingress domain. componentRules optionally contains
+// rules for single components, see IngressComponentPrefixes. If shadow is set,
+// the rules are not enforced, see policiesToTypedConfig.
 func BuildIngressEnvoyFilterSpecForHelmChart(
-	cluster *controller.Cluster, rule *ACLRule, alwaysAllowedCIDRs []string, istioLabels map[string]string,
+	cluster *controller.Cluster, rules []acl.ACLRule, componentRules map[string][]acl.ACLRule,
+	alwaysAllowedCIDRs []string, istioLabels map[string]string, shadow bool,
 ) map[string]interface{} {
 	seedIngressDomain := helper.GetSeedIngressDomain(cluster.Seed)
 	if seedIngressDomain != "" {
@@ -61,7 +91,7 @@ func BuildIngressEnvoyFilterSpecForHelmChart(
 				"labels": istioLabels,
 			},
 			"configPatches": []map[string]interface{}{
-				CreateIngressConfigPatchFromRule(rule, seedIngressDomain, shootID, alwaysAllowedCIDRs),
+				CreateIngressConfigPatchFromRule(rules, componentRules, seedIngressDomain, shootID, alwaysAllowedCIDRs, shadow),
 			},
 		}
 	}
@@ -69,16 +99,19 @@ func BuildIngressEnvoyFilterSpecForHelmChart(
 }
 
 // BuildVPNEnvoyFilterSpecForHelmChart assembles EnvoyFilter patches for VPN.
+// If shadow is set, the rules are not enforced, see policiesToTypedConfig.
 func BuildVPNEnvoyFilterSpecForHelmChart(
-	cluster *controller.Cluster, rule *ACLRule, alwaysAllowedCIDRs []string, istioLabels map[string]string,
+	cluster *controller.Cluster, rules []acl.ACLRule, alwaysAllowedCIDRs []string, istioLabels map[string]string, shadow bool,
 ) map[string]interface{} {
 	return buildProxyEnvoyFilterSpecForHelmChart(httpProxyFilterOptions{
-		Rule:               rule,
+		Rules:              rules,
 		ShortShootID:       helper.ComputeShortShootID(cluster.Shoot),
 		TechnicalShootID:   cluster.Shoot.Status.TechnicalID,
 		AlwaysAllowedCIDRs: alwaysAllowedCIDRs,
 		IstioLabels:        istioLabels,
+		Shadow:             shadow,
 
+		Endpoint:   statEndpointVPN,
 		NameSuffix: "-tls-tunnel",
 		Header:     "reversed-vpn",
 		Port:       8132,
@@ -86,33 +119,40 @@ func BuildVPNEnvoyFilterSpecForHelmChart(
 }
 
 // BuildHTTPProxyEnvoyFilterSpecForHelmChart assembles EnvoyFilter patches for the unified HTTP proxy port.
+// If shadow is set, the rules are not enforced, see policiesToTypedConfig.
 func BuildHTTPProxyEnvoyFilterSpecForHelmChart(
-	cluster *controller.Cluster, rule *ACLRule, alwaysAllowedCIDRs []string, istioLabels map[string]string,
+	cluster *controller.Cluster, rules []acl.ACLRule, alwaysAllowedCIDRs []string, istioLabels map[string]string, shadow bool,
 ) map[string]interface{} {
 	return buildProxyEnvoyFilterSpecForHelmChart(httpProxyFilterOptions{
-		Rule:               rule,
+		Rules:              rules,
 		ShortShootID:       helper.ComputeShortShootID(cluster.Shoot),
 		TechnicalShootID:   cluster.Shoot.Status.TechnicalID,
 		AlwaysAllowedCIDRs: alwaysAllowedCIDRs,
 		IstioLabels:        istioLabels,
+		Shadow:             shadow,
 
+		Endpoint:   statEndpointHTTPProxy,
 		NameSuffix: "-http-proxy",
 		Header:     "X-Gardener-Destination",
 		Port:       8443,
 	})
 }
 
-// CreateAPIConfigPatchFromRule combines an ACLRule, the first entry  of the
-// hosts list and the alwaysAllowedCIDRs into a network filter patch that can be
-// applied to the `GATEWAY` network filter chain matching the host.
+// CreateAPIConfigPatchFromRule combines an ordered list of ACLRules, the first
+// entry of the hosts list and the alwaysAllowedCIDRs into a network filter patch
+// that can be applied to the `GATEWAY` network filter chain matching the host.
+// The stats of the filter are prefixed with statPrefix. If shadow is set, the
+// rules are not enforced, see policiesToTypedConfig.
 func CreateAPIConfigPatchFromRule(
-	rule *ACLRule, hosts, alwaysAllowedCIDRs []string,
+	rules []acl.ACLRule, hosts, alwaysAllowedCIDRs []string, statPrefix string, shadow bool,
 ) (map[string]interface{}, error) {
 	if len(hosts) == 0 {
 		return nil, ErrNoHostsGiven
 	}
 	rbacName := "acl-api"
-	principals := ruleCIDRsToPrincipal(rule, alwaysAllowedCIDRs)
+	policies := rulesToPolicies(rbacName, rules, alwaysAllowedCIDRs, []map[string]interface{}{
+		{"any": true},
+	})
 
 	return map[string]interface{}{
 		"applyTo": "NETWORK_FILTER",
@@ -129,17 +169,95 @@ func CreateAPIConfigPatchFromRule(
 				},
 			},
 		},
-		"patch": principalsToPatch(rbacName, rule.Action, "network", principals),
+		"patch": map[string]interface{}{
+			"operation": "INSERT_FIRST",
+			"value": map[string]interface{}{
+				"name":         rbacName,
+				"typed_config": policiesToTypedConfig(rulesAction(rules), "network", policies, statPrefix, shadow),
+			},
+		},
 	}, nil
 }
 
 // CreateIngressConfigPatchFromRule creates a network filter patch that can be
 // applied to the `GATEWAY` network filter chain matching the wildcard ingress domain.
+//
+// The components in componentRules get their own policies matching their host
+// names only. All other hosts of the shoot, including the hosts of components
+// unknown to the extension, are matched by the policies of the shoot-wide rules.
+// If shadow is set, the rules are not enforced, see policiesToTypedConfig.
 func CreateIngressConfigPatchFromRule(
-	rule *ACLRule, seedIngressDomain, shootID string, alwaysAllowedCIDRs []string,
+	rules []acl.ACLRule, componentRules map[string][]acl.ACLRule, seedIngressDomain, shootID string, alwaysAllowedCIDRs []string,
+	shadow bool,
 ) map[string]interface{} {
 	rbacName := "acl-ingress"
 	ingressSuffix := "-" + shootID + "." + seedIngressDomain
+	shootPermission := map[string]interface{}{
+		"requested_server_name": map[string]interface{}{
+			"suffix": ingressSuffix,
+		},
+	}
+
+	var policies map[string]interface{}
+	action := rulesAction(rules)
+	if len(componentRules) == 0 {
+		policies = rulesToPolicies(shootID, rules, alwaysAllowedCIDRs, []map[string]interface{}{shootPermission})
+	} else {
+		// Components can have rules with different actions, so all policies are
+		// rendered for the ALLOW action.
+		action = "ALLOW"
+		policies = map[string]interface{}{}
+
+		var componentPermissions []map[string]interface{}
+		for _, component := range slices.Sorted(maps.Keys(componentRules)) {
+			prefix, ok := IngressComponentPrefixes[component]
+			if !ok {
+				continue
+			}
+			componentPermission := map[string]interface{}{
+				"requested_server_name": map[string]interface{}{
+					"exact": prefix + ingressSuffix,
+				},
+			}
+			componentPermissions = append(componentPermissions, componentPermission)
+			maps.Copy(policies, rulesToAllowPolicies(
+				shootID+"-"+component, componentRules[component], alwaysAllowedCIDRs,
+				[]map[string]interface{}{componentPermission},
+			))
+		}
+
+		if len(componentPermissions) > 0 {
+			shootPermission = map[string]interface{}{
+				"and_rules": map[string]interface{}{
+					"rules": []map[string]interface{}{
+						shootPermission,
+						{"not_rule": map[string]interface{}{
+							"or_rules": map[string]interface{}{"rules": componentPermissions},
+						}},
+					},
+				},
+			}
+		}
+		maps.Copy(policies, rulesToAllowPolicies(
+			shootID, rules, alwaysAllowedCIDRs, []map[string]interface{}{shootPermission},
+		))
+	}
+
+	if action == "ALLOW" {
+		// The filter applies to the ingress traffic of all shoots, make sure not
+		// to block the traffic of other shoots.
+		policies[shootID+"-inverse"] = map[string]interface{}{
+			"permissions": []map[string]interface{}{{
+				"not_rule": map[string]interface{}{
+					"requested_server_name": map[string]interface{}{
+						"suffix": ingressSuffix,
+					},
+				},
+			}},
+			"principals": allAddressesPrincipals(),
+		}
+	}
+
 	return map[string]interface{}{
 		"applyTo": "NETWORK_FILTER",
 		"match": map[string]interface{}{
@@ -154,58 +272,21 @@ func CreateIngressConfigPatchFromRule(
 		"patch": map[string]interface{}{
 			"operation": "INSERT_FIRST",
 			"value": map[string]interface{}{
-				"name": rbacName,
-				"typed_config": map[string]interface{}{
-					"@type": "type.googleapis.com/envoy.extensions.filters.network.rbac.v3.RBAC",
-					"rules": map[string]interface{}{
-						"action": "ALLOW",
-						"policies": map[string]interface{}{
-							shootID + "-inverse": map[string]interface{}{
-								"permissions": []map[string]interface{}{{
-									"not_rule": map[string]interface{}{
-										"requested_server_name": map[string]interface{}{
-											"suffix": ingressSuffix,
-										},
-									},
-								}},
-								"principals": []map[string]interface{}{
-									{
-										"remote_ip": map[string]interface{}{
-											"address_prefix": "0.0.0.0",
-											"prefix_len":     0,
-										},
-									},
-									{
-										"remote_ip": map[string]interface{}{
-											"address_prefix": "::",
-											"prefix_len":     0,
-										},
-									},
-								},
-							},
-							shootID: map[string]interface{}{
-								"permissions": []map[string]interface{}{{
-									"requested_server_name": map[string]interface{}{
-										"suffix": ingressSuffix,
-									},
-								}},
-								"principals": ruleCIDRsToPrincipal(rule, alwaysAllowedCIDRs),
-							},
-						},
-					},
-					"stat_prefix": "envoyrbac",
-				},
+				"name":         rbacName,
+				"typed_config": policiesToTypedConfig(action, "network", policies, statPrefix(shootID, statEndpointIngress), shadow),
 			},
 		},
 	}
 }
 
 type httpProxyFilterOptions struct {
-	Rule                           *ACLRule
+	Rules                          []acl.ACLRule
 	ShortShootID, TechnicalShootID string
 	AlwaysAllowedCIDRs             []string
 	IstioLabels                    map[string]string
+	Shadow                         bool
 
+	Endpoint   string
 	NameSuffix string
 	Header     string
 	Port       uint32
@@ -226,6 +307,23 @@ func buildProxyEnvoyFilterSpecForHelmChart(p httpProxyFilterOptions) map[string]
 			"contains": "." + p.TechnicalShootID + ".",
 		},
 	}
+	policies := rulesToPolicies(p.ShortShootID, p.Rules, p.AlwaysAllowedCIDRs, []map[string]interface{}{{
+		"header": headerMatcher,
+	}})
+	action := rulesAction(p.Rules)
+	if action == "ALLOW" {
+		// The filter applies to the traffic of all shoots on this listener, make
+		// sure not to block the traffic of other shoots.
+		policies[p.ShortShootID+"-inverse"] = map[string]interface{}{
+			"permissions": []map[string]interface{}{{
+				"not_rule": map[string]interface{}{
+					"header": headerMatcher,
+				},
+			}},
+			"principals": allAddressesPrincipals(),
+		}
+	}
+
 	configPatch := map[string]interface{}{
 		"applyTo": "HTTP_FILTER",
 		"match": map[string]interface{}{
@@ -237,43 +335,8 @@ func buildProxyEnvoyFilterSpecForHelmChart(p httpProxyFilterOptions) map[string]
 		"patch": map[string]interface{}{
 			"operation": "INSERT_FIRST",
 			"value": map[string]interface{}{
-				"name": rbacName,
-				"typed_config": map[string]interface{}{
-					"@type": "type.googleapis.com/envoy.extensions.filters.http.rbac.v3.RBAC",
-					"rules": map[string]interface{}{
-						"action": "ALLOW",
-						"policies": map[string]interface{}{
-							p.ShortShootID + "-inverse": map[string]interface{}{
-								"permissions": []map[string]interface{}{{
-									"not_rule": map[string]interface{}{
-										"header": headerMatcher,
-									},
-								}},
-								"principals": []map[string]interface{}{
-									{
-										"remote_ip": map[string]interface{}{
-											"address_prefix": "0.0.0.0",
-											"prefix_len":     0,
-										},
-									},
-									{
-										"remote_ip": map[string]interface{}{
-											"address_prefix": "::",
-											"prefix_len":     0,
-										},
-									},
-								},
-							},
-							p.ShortShootID: map[string]interface{}{
-								"permissions": []map[string]interface{}{{
-									"header": headerMatcher,
-								}},
-								"principals": ruleCIDRsToPrincipal(p.Rule, p.AlwaysAllowedCIDRs),
-							},
-						},
-					},
-					"stat_prefix": "envoyrbac",
-				},
+				"name":         rbacName,
+				"typed_config": policiesToTypedConfig(action, "http", policies, statPrefix(p.ShortShootID, p.Endpoint), p.Shadow),
 			},
 		},
 	}
@@ -291,7 +354,7 @@ func buildProxyEnvoyFilterSpecForHelmChart(p httpProxyFilterOptions) map[string]
 // CreateInternalFilterPatchFromRule combines an ACLRule, the
 // alwaysAllowedCIDRs, and the shootSpecificCIDRs into a filter patch.
 func CreateInternalFilterPatchFromRule(
-	rule *ACLRule,
+	rule *acl.ACLRule,
 	alwaysAllowedCIDRs []string,
 	shootSpecificCIDRs []string,
 ) (map[string]interface{}, error) {
@@ -300,89 +363,270 @@ func CreateInternalFilterPatchFromRule(
 
 	return map[string]interface{}{
 		"name":         rbacName + "-" + strings.ToLower(rule.Type),
-		"typed_config": typedConfigToPatch(rbacName, rule.Action, "network", principals),
+		"typed_config": typedConfigToPatch(rbacName, rule.Action, "network", principals, defaultStatPrefix, false),
 	}, nil
 }
 
-// ruleCIDRsToPrincipal translates a list of strings in the form "0.0.0.0/0"
-// into a list of envoy principals. The function checks for the rule action: If
-// the action is "ALLOW", the alwaysAllowedCIDRs are appended to the principals
-// to guarantee the downstream flow for these CIDRs is not blocked.
-func ruleCIDRsToPrincipal(rule *ACLRule, alwaysAllowedCIDRs []string) []map[string]interface{} {
-	principals := []map[string]interface{}{}
+// rulesToPolicies translates an ordered list of rules into RBAC policies with
+// the given permissions. The rules are evaluated with first-match semantics:
+// every ALLOW rule gets its own policy, which excludes the CIDRs of all DENY
+// rules preceding it. As the policies of an RBAC filter are OR-ed, an address is
+// matched if the first rule matching it is an ALLOW rule. The
+// alwaysAllowedCIDRs are added to the policy of the first ALLOW rule, so they
+// are never blocked.
+//
+// A list without any ALLOW rule is rendered into a single policy for the DENY
+// action, see rulesAction and denyRulesToPrincipals.
+func rulesToPolicies(
+	policyName string, rules []acl.ACLRule, alwaysAllowedCIDRs []string, permissions []map[string]interface{},
+) map[string]interface{} {
+	policies := map[string]interface{}{}
 
-	for _, cidr := range rule.Cidrs {
-		prefix, length, err := getPrefixAndPrefixLength(cidr)
-		if err != nil {
+	if !containsAllowRule(rules) {
+		policies[policyName] = map[string]interface{}{
+			"permissions": permissions,
+			"principals":  denyRulesToPrincipals(rules, alwaysAllowedCIDRs),
+		}
+		return policies
+	}
+
+	var (
+		deniedRules        []acl.ACLRule
+		alwaysAllowedAdded bool
+	)
+	for i := range rules {
+		rule := &rules[i]
+		if !isAllowRule(rule) {
+			deniedRules = append(deniedRules, *rule)
 			continue
 		}
-		principals = append(principals, map[string]interface{}{
-			strings.ToLower(rule.Type): map[string]interface{}{
-				"address_prefix": prefix,
-				"prefix_len":     length,
+
+		var principals []map[string]interface{}
+		switch {
+		case len(deniedRules) > 0:
+			principals = []map[string]interface{}{{
+				"and_ids": map[string]interface{}{
+					"ids": []map[string]interface{}{
+						{"or_ids": map[string]interface{}{"ids": rulesToPrincipals([]acl.ACLRule{*rule}, nil)}},
+						{"not_id": map[string]interface{}{
+							"or_ids": map[string]interface{}{"ids": rulesToPrincipals(deniedRules, nil)},
+						}},
+					},
+				},
+			}}
+			if !alwaysAllowedAdded {
+				principals = append(principals, cidrsToPrincipals("remote_ip", alwaysAllowedCIDRs)...)
+			}
+		case !alwaysAllowedAdded:
+			principals = rulesToPrincipals([]acl.ACLRule{*rule}, alwaysAllowedCIDRs)
+		default:
+			principals = rulesToPrincipals([]acl.ACLRule{*rule}, nil)
+		}
+		alwaysAllowedAdded = true
+
+		name := policyName
+		if len(rules) > 1 {
+			name = fmt.Sprintf("%s-%d", policyName, i)
+		}
+		policies[name] = map[string]interface{}{
+			"permissions": permissions,
+			"principals":  principals,
+		}
+	}
+
+	return policies
+}
+
+// rulesToAllowPolicies is like rulesToPolicies, but always renders policies for
+// the ALLOW action. A list of DENY rules only is translated into a policy
+// matching every address not covered by the rules.
+func rulesToAllowPolicies(
+	policyName string, rules []acl.ACLRule, alwaysAllowedCIDRs []string, permissions []map[string]interface{},
+) map[string]interface{} {
+	if containsAllowRule(rules) {
+		return rulesToPolicies(policyName, rules, alwaysAllowedCIDRs, permissions)
+	}
+
+	principals := []map[string]interface{}{{
+		"not_id": map[string]interface{}{
+			"or_ids": map[string]interface{}{"ids": rulesToPrincipals(rules, nil)},
+		},
+	}}
+
+	return map[string]interface{}{
+		policyName: map[string]interface{}{
+			"permissions": permissions,
+			"principals":  append(principals, cidrsToPrincipals("remote_ip", alwaysAllowedCIDRs)...),
+		},
+	}
+}
+
+// denyRulesToPrincipals translates a list of DENY rules into principals for the
+// DENY action. The principals match the CIDRs of all rules, except for the
+// alwaysAllowedCIDRs, which must stay reachable even if they are covered by a
+// denied CIDR.
+func denyRulesToPrincipals(rules []acl.ACLRule, alwaysAllowedCIDRs []string) []map[string]interface{} {
+	principals := rulesToPrincipals(rules, nil)
+
+	alwaysAllowedPrincipals := cidrsToPrincipals("remote_ip", alwaysAllowedCIDRs)
+	if len(alwaysAllowedPrincipals) == 0 {
+		return principals
+	}
+
+	return []map[string]interface{}{{
+		"and_ids": map[string]interface{}{
+			"ids": []map[string]interface{}{
+				{"or_ids": map[string]interface{}{"ids": principals}},
+				{"not_id": map[string]interface{}{
+					"or_ids": map[string]interface{}{"ids": alwaysAllowedPrincipals},
+				}},
 			},
-		})
+		},
+	}}
+}
+
+// rulesAction returns the RBAC action for an ordered list of rules. As soon as
+// the list contains an ALLOW rule, addresses not matching any rule are denied,
+// so the policies are evaluated with the ALLOW action. A list of DENY rules
+// only is evaluated with the DENY action.
+func rulesAction(rules []acl.ACLRule) string {
+	if containsAllowRule(rules) {
+		return "ALLOW"
 	}
+	return "DENY"
+}
+
+func containsAllowRule(rules []acl.ACLRule) bool {
+	for i := range rules {
+		if isAllowRule(&rules[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isAllowRule(rule *acl.ACLRule) bool {
+	return strings.EqualFold(rule.Action, "ALLOW")
+}
 
+// ruleCIDRsToPrincipal translates a list of strings in the form "0.0.0.0/0"
+// into a list of envoy principals. The function checks for the rule action: If
+// the action is "ALLOW", the alwaysAllowedCIDRs are added to the principals
+// to guarantee the downstream flow for these CIDRs is not blocked.
+func ruleCIDRsToPrincipal(rule *acl.ACLRule, alwaysAllowedCIDRs []string) []map[string]interface{} {
 	// if the rule has action "ALLOW" (which means "limit the access to only the
 	// specified IPs", we need to insert the node CIDR range to not block
 	// cluster-internal communication)
-	if rule.Action == "ALLOW" {
-		for _, cidr := range alwaysAllowedCIDRs {
-			prefix, length, err := getPrefixAndPrefixLength(cidr)
-			if err != nil {
-				continue
-			}
-			principals = append(principals, map[string]interface{}{
-				"remote_ip": map[string]interface{}{
-					"address_prefix": prefix,
-					"prefix_len":     length,
-				},
-			})
+	if !isAllowRule(rule) {
+		alwaysAllowedCIDRs = nil
+	}
+
+	return rulesToPrincipals([]acl.ACLRule{*rule}, alwaysAllowedCIDRs)
+}
+
+// rulesToPrincipals translates the CIDRs of the given rules and the
+// alwaysAllowedCIDRs into a list of envoy principals matching any of them. The
+// CIDRs are grouped by the principal type of their rule (the alwaysAllowedCIDRs
+// are of type "remote_ip") and every group is minimized with an
+// aclhelper.CIDRSet, so the number of principals evaluated per connection is as
+// small as possible.
+// Invalid CIDRs are skipped.
+func rulesToPrincipals(rules []acl.ACLRule, alwaysAllowedCIDRs []string) []map[string]interface{} {
+	var (
+		principalTypes []string
+		sets           = map[string]*aclhelper.CIDRSet{}
+	)
+	insert := func(principalType string, cidrs []string) {
+		if _, ok := sets[principalType]; !ok {
+			principalTypes = append(principalTypes, principalType)
+			sets[principalType] = &aclhelper.CIDRSet{}
 		}
+		sets[principalType].InsertCIDRs(cidrs...)
+	}
+
+	for i := range rules {
+		insert(strings.ToLower(rules[i].Type), rules[i].Cidrs)
+	}
+	if len(alwaysAllowedCIDRs) > 0 {
+		insert("remote_ip", alwaysAllowedCIDRs)
 	}
 
+	principals := []map[string]interface{}{}
+	for _, principalType := range principalTypes {
+		principals = append(principals, prefixesToPrincipals(principalType, sets[principalType].Prefixes())...)
+	}
 	return principals
 }
 
-func getPrefixAndPrefixLength(cidr string) (prefix string, prefixLen int, err error) {
-	// rule gets validated early in the code
-	ip, mask, err := net.ParseCIDR(cidr)
-	if err != nil {
-		return "", 0, err
+// cidrsToPrincipals translates a list of CIDRs into a minimized list of envoy
+// principals of the given type. Invalid CIDRs are skipped.
+func cidrsToPrincipals(principalType string, cidrs []string) []map[string]interface{} {
+	return prefixesToPrincipals(principalType, aclhelper.NewCIDRSet(cidrs...).Prefixes())
+}
+
+func prefixesToPrincipals(principalType string, prefixes []netip.Prefix) []map[string]interface{} {
+	principals := []map[string]interface{}{}
+
+	for _, prefix := range prefixes {
+		principals = append(principals, map[string]interface{}{
+			principalType: map[string]interface{}{
+				"address_prefix": prefix.Addr().String(),
+				"prefix_len":     prefix.Bits(),
+			},
+		})
 	}
-	prefixLen, _ = mask.Mask.Size()
 
-	// TODO use ip here or the one from the mask?
-	return ip.String(), prefixLen, nil
+	return principals
 }
 
-func principalsToPatch(
-	rbacName, ruleAction, filterType string, principals []map[string]interface{},
-) map[string]interface{} {
-	return map[string]interface{}{
-		"operation": "INSERT_FIRST",
-		"value": map[string]interface{}{
-			"name":         rbacName,
-			"typed_config": typedConfigToPatch(rbacName, ruleAction, filterType, principals),
-		},
-	}
+// allAddressesPrincipals returns principals matching every IPv4 and IPv6 address.
+func allAddressesPrincipals() []map[string]interface{} {
+	return cidrsToPrincipals("remote_ip", []string{"0.0.0.0/0", "::/0"})
 }
 
-func typedConfigToPatch(rbacName, ruleAction, filterType string, principals []map[string]interface{}) map[string]interface{} {
-	return map[string]interface{}{
-		"@type":       "type.googleapis.com/envoy.extensions.filters." + filterType + ".rbac.v3.RBAC",
-		"stat_prefix": "envoyrbac",
-		"rules": map[string]interface{}{
-			"action": strings.ToUpper(ruleAction),
-			"policies": map[string]interface{}{
-				rbacName: map[string]interface{}{
-					"permissions": []map[string]interface{}{
-						{"any": true},
-					},
-					"principals": principals,
-				},
+// typedConfigToPatch translates principals into the typed config of an RBAC
+// filter with a single policy. If shadow is set, the policy is not enforced, see
+// policiesToTypedConfig.
+func typedConfigToPatch(
+	rbacName, ruleAction, filterType string, principals []map[string]interface{}, statPrefix string, shadow bool,
+) map[string]interface{} {
+	return policiesToTypedConfig(ruleAction, filterType, map[string]interface{}{
+		rbacName: map[string]interface{}{
+			"permissions": []map[string]interface{}{
+				{"any": true},
 			},
+			"principals": principals,
 		},
+	}, statPrefix, shadow)
+}
+
+// policiesToTypedConfig translates RBAC policies into the typed config of an
+// RBAC filter, whose stats are prefixed with statPrefix. If shadow is set, the
+// policies are rendered as shadow_rules, which are evaluated and counted in
+// stats prefixed with `<statPrefix>shadow_`, but not enforced.
+func policiesToTypedConfig(
+	ruleAction, filterType string, policies map[string]interface{}, statPrefix string, shadow bool,
+) map[string]interface{} {
+	typedConfig := map[string]interface{}{
+		"@type": "type.googleapis.com/envoy.extensions.filters." + filterType + ".rbac.v3.RBAC",
+	}
+	if filterType == "http" {
+		// The HTTP filter has no stat_prefix of its own, its stats are emitted
+		// below the prefix of the HTTP connection manager.
+		typedConfig["rules_stat_prefix"] = statPrefix
+	} else {
+		typedConfig["stat_prefix"] = statPrefix
+	}
+	rules := map[string]interface{}{
+		"action":   strings.ToUpper(ruleAction),
+		"policies": policies,
+	}
+
+	if shadow {
+		typedConfig["shadow_rules"] = rules
+		typedConfig["shadow_rules_stat_prefix"] = statPrefix + "shadow_"
+	} else {
+		typedConfig["rules"] = rules
 	}
+	return typedConfig
 }
@@ -10,6 +10,8 @@ import (
 	. "github.com/onsi/gomega"
 	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl"
 )
 
 var _ = Describe("EnvoyFilter Unit Tests", func() {
@@ -49,12 +51,49 @@ var _ = Describe("EnvoyFilter Unit Tests", func() {
 					"app":   "istio-ingressgateway",
 					"istio": "ingressgateway",
 				}
-				result, err := BuildAPIEnvoyFilterSpecForHelmChart(rule, hosts, alwaysAllowedCIDRs, labels)
+				result, err := BuildAPIEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, hosts, alwaysAllowedCIDRs, labels, false)
 
 				Expect(err).ToNot(HaveOccurred())
 				checkIfMapEqualsYAML(result, "apiEnvoyFilterSpecWithOneAllowRule.yaml")
 			})
 		})
+
+		When("there is an extension resource with one deny rule", func() {
+			It("Should create a envoyFilter spec denying the CIDRs except for the always allowed ones", func() {
+				rule := createRule("DENY", "remote_ip", "10.180.0.0/16")
+				hosts := []string{
+					"api.test.garden.s.testseed.dev.ske.eu01.stackit.cloud",
+				}
+				labels := map[string]string{
+					"app":   "istio-ingressgateway",
+					"istio": "ingressgateway",
+				}
+				result, err := BuildAPIEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, hosts, alwaysAllowedCIDRs, labels, false)
+
+				Expect(err).ToNot(HaveOccurred())
+				checkIfMapEqualsYAML(result, "apiEnvoyFilterSpecWithOneDenyRule.yaml")
+			})
+		})
+
+		When("there is an extension resource with an ordered list of rules", func() {
+			It("Should exclude the CIDRs of preceding DENY rules from ALLOW rules", func() {
+				rules := []acl.ACLRule{
+					*createRule("DENY", "remote_ip", "10.1.2.3/32"),
+					*createRule("ALLOW", "remote_ip", "10.0.0.0/8"),
+				}
+				hosts := []string{
+					"api.test.garden.s.testseed.dev.ske.eu01.stackit.cloud",
+				}
+				labels := map[string]string{
+					"app":   "istio-ingressgateway",
+					"istio": "ingressgateway",
+				}
+				result, err := BuildAPIEnvoyFilterSpecForHelmChart(cluster, rules, hosts, alwaysAllowedCIDRs, labels, false)
+
+				Expect(err).ToNot(HaveOccurred())
+				checkIfMapEqualsYAML(result, "apiEnvoyFilterSpecWithOrderedRules.yaml")
+			})
+		})
 	})
 
 	Describe("BuildIngressEnvoyFilterSpecForHelmChart", func() {
@@ -65,10 +104,20 @@ var _ = Describe("EnvoyFilter Unit Tests", func() {
 					"app":   "istio-ingressgateway",
 					"istio": "ingressgateway",
 				}
-				ingressEnvoyFilterSpec := BuildIngressEnvoyFilterSpecForHelmChart(cluster, rule, alwaysAllowedCIDRs, labels)
+				ingressEnvoyFilterSpec := BuildIngressEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, nil, alwaysAllowedCIDRs, labels, false)
 
 				checkIfMapEqualsYAML(ingressEnvoyFilterSpec, "ingressEnvoyFilterSpecWithOneAllowRule.yaml")
 			})
+			It("Should create a envoyFilter spec only denying the CIDRs of a deny rule for this shoot", func() {
+				rule := createRule("DENY", "remote_ip", "10.180.0.0/16")
+				labels := map[string]string{
+					"app":   "istio-ingressgateway",
+					"istio": "ingressgateway",
+				}
+				ingressEnvoyFilterSpec := BuildIngressEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, nil, alwaysAllowedCIDRs, labels, false)
+
+				checkIfMapEqualsYAML(ingressEnvoyFilterSpec, "ingressEnvoyFilterSpecWithOneDenyRule.yaml")
+			})
 			It("Should not create an envoyFilter spec when seed has no ingress", func() {
 				rule := createRule("ALLOW", "remote_ip", "10.180.0.0/16")
 				cluster.Seed.Spec.Ingress = nil
@@ -76,10 +125,27 @@ var _ = Describe("EnvoyFilter Unit Tests", func() {
 					"app":   "istio-ingressgateway",
 					"istio": "ingressgateway",
 				}
-				ingressEnvoyFilterSpec := BuildIngressEnvoyFilterSpecForHelmChart(cluster, rule, alwaysAllowedCIDRs, labels)
+				ingressEnvoyFilterSpec := BuildIngressEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, nil, alwaysAllowedCIDRs, labels, false)
 				Expect(ingressEnvoyFilterSpec["ingressEnvoyFilterSpec"]).To(BeNil())
 			})
 		})
+
+		When("there is an extension resource with component rules", func() {
+			It("Should create an envoyFilter spec with a policy per component", func() {
+				rule := createRule("ALLOW", "remote_ip", "10.180.0.0/16")
+				componentRules := map[string][]acl.ACLRule{
+					"plutono":    {*createRule("ALLOW", "remote_ip", "0.0.0.0/0")},
+					"prometheus": {*createRule("DENY", "remote_ip", "10.181.0.0/16")},
+				}
+				labels := map[string]string{
+					"app":   "istio-ingressgateway",
+					"istio": "ingressgateway",
+				}
+				ingressEnvoyFilterSpec := BuildIngressEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, componentRules, alwaysAllowedCIDRs, labels, false)
+
+				checkIfMapEqualsYAML(ingressEnvoyFilterSpec, "ingressEnvoyFilterSpecWithComponentRules.yaml")
+			})
+		})
 	})
 
 	Describe("BuildVPNEnvoyFilterSpecForHelmChart", func() {
@@ -90,11 +156,72 @@ var _ = Describe("EnvoyFilter Unit Tests", func() {
 					"app":   "istio-ingressgateway",
 					"istio": "ingressgateway",
 				}
-				result := BuildVPNEnvoyFilterSpecForHelmChart(cluster, rule, alwaysAllowedCIDRs, labels)
+				result := BuildVPNEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, alwaysAllowedCIDRs, labels, false)
 
 				checkIfMapEqualsYAML(result, "vpnEnvoyFilterSpecWithOneAllowRule.yaml")
 			})
 		})
+
+		When("there is one shoot with a deny rule", func() {
+			It("Should create a envoyFilter spec only denying the CIDRs for this shoot", func() {
+				rule := createRule("DENY", "remote_ip", "10.180.0.0/16")
+				labels := map[string]string{
+					"app":   "istio-ingressgateway",
+					"istio": "ingressgateway",
+				}
+				result := BuildVPNEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, alwaysAllowedCIDRs, labels, false)
+
+				checkIfMapEqualsYAML(result, "vpnEnvoyFilterSpecWithOneDenyRule.yaml")
+			})
+		})
+
+		When("there is one shoot with an ordered list of rules", func() {
+			It("Should create a envoyFilter spec matching the expected one", func() {
+				rules := []acl.ACLRule{
+					*createRule("DENY", "remote_ip", "10.1.2.3/32"),
+					*createRule("ALLOW", "remote_ip", "10.0.0.0/8"),
+				}
+				labels := map[string]string{
+					"app":   "istio-ingressgateway",
+					"istio": "ingressgateway",
+				}
+				result := BuildVPNEnvoyFilterSpecForHelmChart(cluster, rules, alwaysAllowedCIDRs, labels, false)
+
+				checkIfMapEqualsYAML(result, "vpnEnvoyFilterSpecWithOrderedRules.yaml")
+			})
+		})
+
+		When("there is one shoot with a rule in shadow mode", func() {
+			It("Should create a envoyFilter spec with shadow rules only", func() {
+				rule := createRule("ALLOW", "remote_ip", "10.180.0.0/16")
+				labels := map[string]string{
+					"app":   "istio-ingressgateway",
+					"istio": "ingressgateway",
+				}
+				result := BuildVPNEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, alwaysAllowedCIDRs, labels, true)
+
+				checkIfMapEqualsYAML(result, "vpnEnvoyFilterSpecWithOneAllowRuleInShadowMode.yaml")
+			})
+
+			It("Should prefix the stats of the shadow rules per endpoint", func() {
+				rules := []acl.ACLRule{*createRule("ALLOW", "remote_ip", "10.180.0.0/16")}
+				hosts := []string{"api.test.garden.s.testseed.dev.ske.eu01.stackit.cloud"}
+				shadowStatPrefix := func(spec map[string]interface{}) interface{} {
+					patch := spec["configPatches"].([]map[string]interface{})[0]["patch"].(map[string]interface{})
+					return patch["value"].(map[string]interface{})["typed_config"].(map[string]interface{})["shadow_rules_stat_prefix"]
+				}
+
+				apiSpec, err := BuildAPIEnvoyFilterSpecForHelmChart(cluster, rules, hosts, alwaysAllowedCIDRs, nil, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(shadowStatPrefix(apiSpec)).To(Equal("acl_bar--foo_api_shadow_"))
+				Expect(shadowStatPrefix(BuildVPNEnvoyFilterSpecForHelmChart(cluster, rules, alwaysAllowedCIDRs, nil, true))).
+					To(Equal("acl_bar--foo_vpn_shadow_"))
+				Expect(shadowStatPrefix(BuildHTTPProxyEnvoyFilterSpecForHelmChart(cluster, rules, alwaysAllowedCIDRs, nil, true))).
+					To(Equal("acl_bar--foo_httpproxy_shadow_"))
+				Expect(shadowStatPrefix(BuildIngressEnvoyFilterSpecForHelmChart(cluster, rules, nil, alwaysAllowedCIDRs, nil, true))).
+					To(Equal("acl_bar--foo_ingress_shadow_"))
+			})
+		})
 	})
 
 	Describe("BuildHTTPProxyEnvoyFilterSpecForHelmChart", func() {
@@ -105,7 +232,7 @@ var _ = Describe("EnvoyFilter Unit Tests", func() {
 					"app":   "istio-ingressgateway",
 					"istio": "ingressgateway",
 				}
-				result := BuildHTTPProxyEnvoyFilterSpecForHelmChart(cluster, rule, alwaysAllowedCIDRs, labels)
+				result := BuildHTTPProxyEnvoyFilterSpecForHelmChart(cluster, []acl.ACLRule{*rule}, alwaysAllowedCIDRs, labels, false)
 
 				checkIfMapEqualsYAML(result, "httpProxyEnvoyFilterSpecWithOneAllowRule.yaml")
 			})
@@ -123,6 +250,28 @@ var _ = Describe("EnvoyFilter Unit Tests", func() {
 				checkIfMapEqualsYAML(result, "singleFiltersAllowEntry.yaml")
 			})
 		})
+
+		When("there is an allow rule with a non-canonical CIDR", func() {
+			It("Should render the canonical prefix", func() {
+				rule := createRule("ALLOW", "remote_ip", "::ffff:1.2.3.4/96")
+
+				result, err := CreateInternalFilterPatchFromRule(rule, alwaysAllowedCIDRs, []string{})
+
+				Expect(err).ToNot(HaveOccurred())
+				checkIfMapEqualsYAML(result, "singleFiltersAllowEntry.yaml")
+			})
+		})
+
+		When("there is an allow rule with a lower case action", func() {
+			It("Should still include the always allowed CIDRs", func() {
+				rule := createRule("allow", "REMOTE_IP", "0.0.0.0/0")
+
+				result, err := CreateInternalFilterPatchFromRule(rule, alwaysAllowedCIDRs, []string{})
+
+				Expect(err).ToNot(HaveOccurred())
+				checkIfMapEqualsYAML(result, "singleFiltersAllowEntry.yaml")
+			})
+		})
 	})
 
 	Describe("CreateAPIConfigPatchFromRule", func() {
@@ -130,7 +279,7 @@ var _ = Describe("EnvoyFilter Unit Tests", func() {
 			It("should return the appropriate error", func() {
 				rule := createRule("ALLOW", "remote_ip", "0.0.0.0/0")
 
-				result, err := CreateAPIConfigPatchFromRule(rule, nil, alwaysAllowedCIDRs)
+				result, err := CreateAPIConfigPatchFromRule([]acl.ACLRule{*rule}, nil, alwaysAllowedCIDRs, "", false)
 
 				Expect(err).To(Equal(ErrNoHostsGiven))
 				Expect(result).To(BeNil())
@@ -139,9 +288,8 @@ var _ = Describe("EnvoyFilter Unit Tests", func() {
 	})
 })
 
-//nolint:unparam // action currently only accepts ALLOW but that might change, so we leave the parameterization
-func createRule(action, ruleType, cidr string) *ACLRule {
-	return &ACLRule{
+func createRule(action, ruleType, cidr string) *acl.ACLRule {
+	return &acl.ACLRule{
 		Cidrs: []string{
 			cidr,
 		},
@@ -0,0 +1,282 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+// Database maps country codes and autonomous system numbers to the CIDRs of
+// their addresses. The CIDRs of every country and ASN are aggregated, see
+// aclhelper.CIDRSet. A Database is never modified, so it is safe for
+// concurrent use.
+type Database struct {
+	countries map[string][]string
+	asns      map[uint32][]string
+}
+
+// NewDatabase returns a Database with the given CIDRs by country code and ASN.
+func NewDatabase(countries map[string][]string, asns map[uint32][]string) *Database {
+	return &Database{countries: countries, asns: asns}
+}
+
+// Load reads the CIDRs of the countries and ASNs from the given files. Files
+// with the extension ".mmdb" are read as MMDB files, see ParseCountriesMMDB and
+// ParseASNsMMDB, all other files as CSV files, see ParseCountries and
+// ParseASNs. An empty file name is skipped.
+func Load(countriesFile, asnsFile string) (*Database, error) {
+	db := &Database{}
+	if countriesFile != "" {
+		if err := parseFile(countriesFile, func(data []byte) (err error) {
+			if isMMDB(countriesFile) {
+				db.countries, err = ParseCountriesMMDB(data)
+			} else {
+				db.countries, err = ParseCountries(bytes.NewReader(data))
+			}
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if asnsFile != "" {
+		if err := parseFile(asnsFile, func(data []byte) (err error) {
+			if isMMDB(asnsFile) {
+				db.asns, err = ParseASNsMMDB(data)
+			} else {
+				db.asns, err = ParseASNs(bytes.NewReader(data))
+			}
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// isMMDB checks whether the file with the given name is an MMDB file by its
+// extension.
+func isMMDB(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".mmdb")
+}
+
+func parseFile(name string, parse func(data []byte) error) error {
+	// MMDB files need random access, so the files are read completely
+	data, err := os.ReadFile(name) // #nosec G304 -- the file is configured by the operator
+	if err != nil {
+		return err
+	}
+
+	if err := parse(data); err != nil {
+		return fmt.Errorf("error parsing %s: %w", name, err)
+	}
+	return nil
+}
+
+// Countries returns the CIDRs of the given country codes. It fails with an
+// error listing all country codes missing in the database.
+func (d *Database) Countries(codes []string) (map[string][]string, error) {
+	cidrs := make(map[string][]string, len(codes))
+	var unknown []string
+	for _, code := range codes {
+		c, ok := d.countries[code]
+		if !ok {
+			unknown = append(unknown, code)
+			continue
+		}
+		cidrs[code] = c
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown countries: %s", strings.Join(unknown, ", "))
+	}
+	return cidrs, nil
+}
+
+// ASNs returns the CIDRs of the given autonomous system numbers. It fails with
+// an error listing all ASNs missing in the database.
+func (d *Database) ASNs(asns []uint32) (map[uint32][]string, error) {
+	cidrs := make(map[uint32][]string, len(asns))
+	var unknown []string
+	for _, asn := range asns {
+		c, ok := d.asns[asn]
+		if !ok {
+			unknown = append(unknown, "AS"+strconv.FormatUint(uint64(asn), 10))
+			continue
+		}
+		cidrs[asn] = c
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown ASNs: %s", strings.Join(unknown, ", "))
+	}
+	return cidrs, nil
+}
+
+// ParseCountries parses a CSV file mapping addresses to ISO 3166-1 alpha-2
+// country codes, which are returned in upper case, see parseCSV.
+func ParseCountries(r io.Reader) (map[string][]string, error) {
+	return parseCSV(r, parseCountryCode)
+}
+
+// parseCountryCode returns the given ISO 3166-1 alpha-2 country code in upper
+// case.
+func parseCountryCode(value string) (string, error) {
+	code := strings.ToUpper(value)
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return "", fmt.Errorf("invalid country code %q", value)
+	}
+	return code, nil
+}
+
+// ParseASNs parses a CSV file mapping addresses to autonomous system numbers,
+// which may be prefixed with "AS", see parseCSV.
+func ParseASNs(r io.Reader) (map[uint32][]string, error) {
+	return parseCSV(r, func(value string) (uint32, error) {
+		asn, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(value), "AS"), 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ASN %q", value)
+		}
+		return uint32(asn), nil
+	})
+}
+
+// parseCSV parses the CIDRs by key of a CSV file in the format of the DB-IP
+// ("start,end,key,...") or MaxMind GeoLite2 ASN ("network,key,...") CSV
+// databases. The first line is skipped as a header if its first field is
+// neither an address nor a prefix. The CIDRs of every key are aggregated.
+func parseCSV[K comparable](r io.Reader, parseKey func(value string) (K, error)) (map[K][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	sets := map[K]*aclhelper.CIDRSet{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if line == 1 && isHeader(record) {
+			continue
+		}
+		prefixes, value, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		key, err := parseKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if sets[key] == nil {
+			sets[key] = &aclhelper.CIDRSet{}
+		}
+		sets[key].Insert(prefixes...)
+	}
+
+	return aggregate(sets), nil
+}
+
+// aggregate returns the aggregated CIDRs of the given sets by their key.
+func aggregate[K comparable](sets map[K]*aclhelper.CIDRSet) map[K][]string {
+	cidrs := make(map[K][]string, len(sets))
+	for key, set := range sets {
+		for _, prefix := range set.Prefixes() {
+			cidrs[key] = append(cidrs[key], prefix.String())
+		}
+	}
+	return cidrs
+}
+
+// isHeader checks whether the given record is a header, i.e. its first field is
+// neither an address nor a prefix.
+func isHeader(record []string) bool {
+	field := strings.TrimSpace(record[0])
+	if _, err := netip.ParseAddr(field); err == nil {
+		return false
+	}
+	if _, err := netip.ParsePrefix(field); err == nil {
+		return false
+	}
+	return true
+}
+
+// parseRecord returns the prefixes and the key of a "network,key,..." or
+// "start,end,key,..." record.
+func parseRecord(record []string) ([]netip.Prefix, string, error) {
+	if len(record) >= 2 && strings.Contains(record[0], "/") {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, "", err
+		}
+		return []netip.Prefix{prefix}, strings.TrimSpace(record[1]), nil
+	}
+
+	if len(record) < 3 {
+		return nil, "", fmt.Errorf("expected at least 3 fields, got %d", len(record))
+	}
+	start, err := netip.ParseAddr(strings.TrimSpace(record[0]))
+	if err != nil {
+		return nil, "", err
+	}
+	end, err := netip.ParseAddr(strings.TrimSpace(record[1]))
+	if err != nil {
+		return nil, "", err
+	}
+	prefixes, err := rangePrefixes(start, end)
+	if err != nil {
+		return nil, "", err
+	}
+	return prefixes, strings.TrimSpace(record[2]), nil
+}
+
+// rangePrefixes returns the minimal list of prefixes covering exactly the
+// addresses from start to end, e.g. 10.0.0.0/24 and 10.0.1.0/25 for the range
+// from 10.0.0.0 to 10.0.1.127.
+func rangePrefixes(start, end netip.Addr) ([]netip.Prefix, error) {
+	start, end = start.Unmap(), end.Unmap()
+	if start.BitLen() != end.BitLen() || end.Less(start) {
+		return nil, fmt.Errorf("invalid range from %s to %s", start, end)
+	}
+
+	var prefixes []netip.Prefix
+	for {
+		// find the largest prefix starting at start and ending before end
+		bits := start.BitLen()
+		for bits > 0 {
+			parent := netip.PrefixFrom(start, bits-1)
+			if parent.Masked().Addr() != start || end.Less(lastAddr(parent)) {
+				break
+			}
+			bits--
+		}
+
+		prefix := netip.PrefixFrom(start, bits)
+		prefixes = append(prefixes, prefix)
+		last := lastAddr(prefix)
+		if last == end {
+			return prefixes, nil
+		}
+		start = last.Next()
+	}
+}
+
+// lastAddr returns the last address of the given prefix.
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
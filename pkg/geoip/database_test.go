@@ -0,0 +1,123 @@
+package geoip
+
+import (
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("database", func() {
+	Describe("#ParseCountries", func() {
+		It("should parse and aggregate address ranges", func() {
+			countries, err := ParseCountries(strings.NewReader(`10.0.0.0,10.0.1.127,DE
+10.0.1.128,10.0.1.255,de
+10.1.0.0,10.1.0.255,FR
+2001:db8::,2001:db8:0:ffff:ffff:ffff:ffff:ffff,FR
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(countries).To(Equal(map[string][]string{
+				"DE": {"10.0.0.0/23"},
+				"FR": {"10.1.0.0/24", "2001:db8::/48"},
+			}))
+		})
+
+		It("should fail for invalid lines", func() {
+			_, err := ParseCountries(strings.NewReader(`10.0.0.0,10.0.0.255,DE
+10.0.1.255,10.0.1.0,FR
+`))
+			Expect(err).To(MatchError(ContainSubstring("line 2: invalid range")))
+		})
+
+		It("should fail for an invalid first line", func() {
+			_, err := ParseCountries(strings.NewReader(`10.0.0.255,10.0.0.0,DE
+10.0.1.0,10.0.1.255,FR
+`))
+			Expect(err).To(MatchError(ContainSubstring("line 1: invalid range")))
+		})
+
+		It("should fail for invalid country codes", func() {
+			_, err := ParseCountries(strings.NewReader(`10.0.0.0,10.0.0.255,DE
+10.0.1.0,10.0.1.255,Germany
+`))
+			Expect(err).To(MatchError(`line 2: invalid country code "Germany"`))
+		})
+	})
+
+	Describe("#ParseASNs", func() {
+		It("should parse networks and skip the header", func() {
+			asns, err := ParseASNs(strings.NewReader(`network,autonomous_system_number,autonomous_system_organization
+10.0.0.0/24,64496,"Example, Inc."
+10.0.1.0/24,AS64496,"Example, Inc."
+10.2.0.0/16,64497,Other
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(asns).To(Equal(map[uint32][]string{
+				64496: {"10.0.0.0/23"},
+				64497: {"10.2.0.0/16"},
+			}))
+		})
+
+		It("should fail for invalid ASNs", func() {
+			_, err := ParseASNs(strings.NewReader(`10.0.0.0,10.0.0.255,Example`))
+			Expect(err).To(MatchError(`line 1: invalid ASN "Example"`))
+		})
+	})
+
+	Describe("#Load", func() {
+		It("should load the given files", func() {
+			dir := GinkgoT().TempDir()
+			countriesFile := filepath.Join(dir, "countries.csv")
+			Expect(os.WriteFile(countriesFile, []byte("10.0.0.0,10.0.0.255,DE\n"), 0o600)).To(Succeed())
+
+			db, err := Load(countriesFile, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(db.Countries([]string{"DE"})).To(Equal(map[string][]string{"DE": {"10.0.0.0/24"}}))
+			Expect(db.ASNs(nil)).To(BeEmpty())
+		})
+	})
+
+	Describe("#Countries", func() {
+		It("should fail for unknown country codes", func() {
+			db := NewDatabase(map[string][]string{"DE": {"10.0.0.0/24"}}, nil)
+
+			_, err := db.Countries([]string{"DE", "FR", "AT"})
+			Expect(err).To(MatchError("unknown countries: FR, AT"))
+		})
+	})
+
+	Describe("#ASNs", func() {
+		It("should fail for unknown ASNs", func() {
+			db := NewDatabase(nil, map[uint32][]string{64496: {"10.0.0.0/24"}})
+
+			Expect(db.ASNs([]uint32{64496})).To(Equal(map[uint32][]string{64496: {"10.0.0.0/24"}}))
+			_, err := db.ASNs([]uint32{64496, 64497})
+			Expect(err).To(MatchError("unknown ASNs: AS64497"))
+		})
+	})
+
+	Describe("#rangePrefixes", func() {
+		It("should return the minimal prefixes of the range", func() {
+			Expect(rangePrefixes(netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.1.127"))).To(Equal([]netip.Prefix{
+				netip.MustParsePrefix("10.0.0.1/32"),
+				netip.MustParsePrefix("10.0.0.2/31"),
+				netip.MustParsePrefix("10.0.0.4/30"),
+				netip.MustParsePrefix("10.0.0.8/29"),
+				netip.MustParsePrefix("10.0.0.16/28"),
+				netip.MustParsePrefix("10.0.0.32/27"),
+				netip.MustParsePrefix("10.0.0.64/26"),
+				netip.MustParsePrefix("10.0.0.128/25"),
+				netip.MustParsePrefix("10.0.1.0/25"),
+			}))
+		})
+
+		It("should cover the whole address space", func() {
+			Expect(rangePrefixes(netip.MustParseAddr("0.0.0.0"), netip.MustParseAddr("255.255.255.255"))).To(Equal([]netip.Prefix{
+				netip.MustParsePrefix("0.0.0.0/0"),
+			}))
+		})
+	})
+})
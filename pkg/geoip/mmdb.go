@@ -0,0 +1,427 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+// mmdbMetadataMarker precedes the metadata at the end of an MMDB file, see
+// https://maxmind.github.io/MaxMind-DB/.
+var mmdbMetadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// mmdbDataSectionSeparator is the size of the zero bytes between the search
+// tree and the data section.
+const mmdbDataSectionSeparator = 16
+
+// ParseCountriesMMDB parses an MMDB file in the format of the MaxMind GeoLite2
+// Country or DB-IP Country Lite databases and returns the CIDRs by the ISO
+// 3166-1 alpha-2 code of their `country`. Networks without a country are
+// skipped.
+func ParseCountriesMMDB(data []byte) (map[string][]string, error) {
+	return parseMMDB(data, func(record any) (string, bool, error) {
+		country, ok := lookupMMDB(record, "country", "iso_code").(string)
+		if !ok {
+			return "", false, nil
+		}
+		code, err := parseCountryCode(country)
+		return code, err == nil, err
+	})
+}
+
+// ParseASNsMMDB parses an MMDB file in the format of the MaxMind GeoLite2 ASN or
+// DB-IP ASN Lite databases and returns the CIDRs by their
+// `autonomous_system_number`. Networks without an ASN are skipped.
+func ParseASNsMMDB(data []byte) (map[uint32][]string, error) {
+	return parseMMDB(data, func(record any) (uint32, bool, error) {
+		asn, ok := lookupMMDB(record, "autonomous_system_number").(uint64)
+		if !ok {
+			return 0, false, nil
+		}
+		if asn > math.MaxUint32 {
+			return 0, false, fmt.Errorf("invalid ASN %d", asn)
+		}
+		return uint32(asn), true, nil
+	})
+}
+
+// lookupMMDB returns the value at the given path of nested maps of a decoded
+// MMDB record or nil if it does not exist.
+func lookupMMDB(record any, path ...string) any {
+	for _, key := range path {
+		m, ok := record.(map[string]any)
+		if !ok {
+			return nil
+		}
+		record = m[key]
+	}
+	return record
+}
+
+// parseMMDB walks the search tree of the given MMDB file and returns the
+// aggregated CIDRs of all networks by the key parseKey returns for their
+// record. Records for which parseKey returns false are skipped. In IPv6
+// databases, the IPv4 networks are returned as IPv4 CIDRs and the aliases of the
+// IPv4 subtree (e.g. ::ffff:0:0/96) are skipped.
+func parseMMDB[K comparable](data []byte, parseKey func(record any) (K, bool, error)) (map[K][]string, error) {
+	metadataStart := bytes.LastIndex(data, mmdbMetadataMarker)
+	if metadataStart < 0 {
+		return nil, errors.New("invalid MMDB file: metadata not found")
+	}
+	metadata, _, err := (&mmdbDecoder{data: data[metadataStart+len(mmdbMetadataMarker):]}).decode(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MMDB metadata: %w", err)
+	}
+
+	nodeCount, _ := lookupMMDB(metadata, "node_count").(uint64)
+	recordSize, _ := lookupMMDB(metadata, "record_size").(uint64)
+	ipVersion, _ := lookupMMDB(metadata, "ip_version").(uint64)
+	if recordSize != 24 && recordSize != 28 && recordSize != 32 {
+		return nil, fmt.Errorf("unsupported MMDB record size %d", recordSize)
+	}
+	if ipVersion != 4 && ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported MMDB IP version %d", ipVersion)
+	}
+	treeSize := nodeCount * recordSize / 4
+	if treeSize+mmdbDataSectionSeparator > uint64(metadataStart) {
+		return nil, fmt.Errorf("invalid MMDB file: search tree of %d nodes exceeds the file", nodeCount)
+	}
+
+	w := &mmdbWalker[K]{
+		tree:       data[:treeSize],
+		data:       &mmdbDecoder{data: data[treeSize+mmdbDataSectionSeparator : metadataStart]},
+		nodeCount:  nodeCount,
+		recordSize: recordSize,
+		parseKey:   parseKey,
+		keys:       map[uint64]mmdbKey[K]{},
+		sets:       map[K]*aclhelper.CIDRSet{},
+	}
+
+	bitLen := 32
+	if ipVersion == 6 {
+		bitLen = 128
+		// the IPv4 subtree is the node reached by 96 zero bits, which is also
+		// referenced by aliases like ::ffff:0:0/96
+		w.ipv4Start = 0
+		for i := 0; i < 96 && w.ipv4Start < nodeCount; i++ {
+			w.ipv4Start = w.record(w.ipv4Start, 0)
+		}
+	}
+	if err := w.walk(0, make([]byte, bitLen/8), 0); err != nil {
+		return nil, err
+	}
+	return aggregate(w.sets), nil
+}
+
+// mmdbKey is the cached result of parsing the key of a record.
+type mmdbKey[K comparable] struct {
+	key K
+	ok  bool
+}
+
+// mmdbWalker walks the search tree of an MMDB file and collects the networks
+// of every key.
+type mmdbWalker[K comparable] struct {
+	tree       []byte
+	data       *mmdbDecoder
+	nodeCount  uint64
+	recordSize uint64
+	ipv4Start  uint64
+	parseKey   func(record any) (K, bool, error)
+	// keys caches the keys by the offset of their records, as most records
+	// are shared by many networks
+	keys map[uint64]mmdbKey[K]
+	sets map[K]*aclhelper.CIDRSet
+}
+
+// record returns the left (bit 0) or right (bit 1) record of the given node.
+func (w *mmdbWalker[K]) record(node uint64, bit int) uint64 {
+	b := w.tree[node*w.recordSize/4:]
+	switch w.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+	case 28:
+		if bit == 0 {
+			return uint64(b[3]&0xF0)<<20 | uint64(b[0])<<16 | uint64(b[1])<<8 | uint64(b[2])
+		}
+		return uint64(b[3]&0x0F)<<24 | uint64(b[4])<<16 | uint64(b[5])<<8 | uint64(b[6])
+	default:
+		return uint64(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+// walk visits the given node, which is reached by the first depth bits of addr.
+func (w *mmdbWalker[K]) walk(node uint64, addr []byte, depth int) error {
+	if depth == len(addr)*8 {
+		return errors.New("invalid MMDB file: search tree is deeper than the address length")
+	}
+
+	for bit := range 2 {
+		if bit == 1 {
+			addr[depth/8] |= 0x80 >> (depth % 8)
+		}
+
+		value := w.record(node, bit)
+		switch {
+		case value < w.nodeCount:
+			if value == w.ipv4Start && len(addr) == 16 && !isIPv4Subtree(addr, depth+1) {
+				continue
+			}
+			if err := w.walk(value, addr, depth+1); err != nil {
+				return err
+			}
+		case value > w.nodeCount:
+			if err := w.insert(value-w.nodeCount-mmdbDataSectionSeparator, addr, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the deeper bits are reset by the visited subtrees, so only the bit of
+	// the right subtree is left to reset
+	addr[depth/8] &^= 0x80 >> (depth % 8)
+	return nil
+}
+
+// isIPv4Subtree checks whether the first bits of the given IPv6 address are
+// the 96 zero bits leading to the IPv4 subtree.
+func isIPv4Subtree(addr []byte, bits int) bool {
+	return bits == 96 && bytes.Equal(addr[:12], make([]byte, 12))
+}
+
+// insert adds the network of the first bits of addr to the set of the key of
+// the record at the given offset of the data section.
+func (w *mmdbWalker[K]) insert(offset uint64, addr []byte, bits int) error {
+	key, ok := w.keys[offset]
+	if !ok {
+		record, _, err := w.data.decode(offset)
+		if err != nil {
+			return fmt.Errorf("invalid MMDB record at offset %d: %w", offset, err)
+		}
+		key.key, key.ok, err = w.parseKey(record)
+		if err != nil {
+			return err
+		}
+		w.keys[offset] = key
+	}
+	if !key.ok {
+		return nil
+	}
+
+	ip, _ := netip.AddrFromSlice(addr)
+	prefix := netip.PrefixFrom(ip, bits)
+	if len(addr) == 16 && bits >= 96 && bytes.Equal(addr[:12], make([]byte, 12)) {
+		prefix = netip.PrefixFrom(netip.AddrFrom4([4]byte(addr[12:])), bits-96)
+	}
+
+	if w.sets[key.key] == nil {
+		w.sets[key.key] = &aclhelper.CIDRSet{}
+	}
+	w.sets[key.key].Insert(prefix.Masked())
+	return nil
+}
+
+// MMDB data types, see https://maxmind.github.io/MaxMind-DB/.
+const (
+	mmdbExtended = iota
+	mmdbPointer
+	mmdbString
+	mmdbDouble
+	mmdbBytes
+	mmdbUint16
+	mmdbUint32
+	mmdbMap
+	mmdbInt32
+	mmdbUint64
+	mmdbUint128
+	mmdbArray
+	mmdbContainer
+	mmdbEndMarker
+	mmdbBoolean
+	mmdbFloat
+)
+
+// mmdbDecoder decodes the values of the data section of an MMDB file. Maps are
+// decoded as map[string]any, arrays as []any, unsigned integers up to 64 bits
+// as uint64, signed integers as int64, floating point numbers as float64 and
+// 128 bit integers as []byte.
+type mmdbDecoder struct {
+	data []byte
+}
+
+// decode decodes the value at the given offset and returns it together with the
+// offset of the next value.
+func (d *mmdbDecoder) decode(offset uint64) (any, uint64, error) {
+	return d.decodeValue(offset, 0)
+}
+
+// maxMMDBDepth limits the nesting of maps and arrays to protect against
+// malicious files.
+const maxMMDBDepth = 32
+
+func (d *mmdbDecoder) decodeValue(offset uint64, depth int) (any, uint64, error) {
+	if depth > maxMMDBDepth {
+		return nil, 0, errors.New("maximum nesting depth exceeded")
+	}
+
+	typ, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typ == mmdbPointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		// pointers must not point to pointers, so the value is decoded
+		// directly
+		value, _, err := d.decodeValue(pointer, depth+1)
+		return value, next, err
+	}
+
+	switch typ {
+	case mmdbMap:
+		m := make(map[string]any, size)
+		for range size {
+			var key, value any
+			if key, offset, err = d.decodeValue(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("invalid map key of type %T", key)
+			}
+			if value, offset, err = d.decodeValue(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			m[k] = value
+		}
+		return m, offset, nil
+	case mmdbArray:
+		a := make([]any, 0, size)
+		for range size {
+			var value any
+			if value, offset, err = d.decodeValue(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+		}
+		return a, offset, nil
+	case mmdbBoolean:
+		return size != 0, offset, nil
+	}
+
+	end := offset + size
+	if end > uint64(len(d.data)) {
+		return nil, 0, errors.New("unexpected end of data")
+	}
+	b := d.data[offset:end]
+
+	switch typ {
+	case mmdbString:
+		return string(b), end, nil
+	case mmdbBytes, mmdbUint128:
+		return bytes.Clone(b), end, nil
+	case mmdbDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid size %d of double", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), end, nil
+	case mmdbFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid size %d of float", size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), end, nil
+	case mmdbUint16, mmdbUint32, mmdbUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid size %d of unsigned integer", size)
+		}
+		var value uint64
+		for _, c := range b {
+			value = value<<8 | uint64(c)
+		}
+		return value, end, nil
+	case mmdbInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid size %d of int32", size)
+		}
+		var value uint32
+		for _, c := range b {
+			value = value<<8 | uint32(c)
+		}
+		return int64(int32(value)), end, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported data type %d", typ)
+	}
+}
+
+// decodeControl decodes the control byte (and the extended type and size
+// bytes) at the given offset and returns the type, the size and the offset of
+// the payload. For pointers, the size is the control byte itself.
+func (d *mmdbDecoder) decodeControl(offset uint64) (int, uint64, uint64, error) {
+	next := func() (uint64, error) {
+		if offset >= uint64(len(d.data)) {
+			return 0, errors.New("unexpected end of data")
+		}
+		offset++
+		return uint64(d.data[offset-1]), nil
+	}
+
+	ctrl, err := next()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	typ := int(ctrl >> 5)
+	if typ == mmdbPointer {
+		return typ, ctrl, offset, nil
+	}
+	if typ == mmdbExtended {
+		ext, err := next()
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		typ = int(ext) + 7
+	}
+
+	size := ctrl & 0x1f
+	if size >= 29 {
+		n := size - 28
+		var extra uint64
+		for range n {
+			c, err := next()
+			if err != nil {
+				return 0, 0, 0, err
+			}
+			extra = extra<<8 | c
+		}
+		size = [...]uint64{29, 285, 65821}[n-1] + extra
+	}
+	return typ, size, offset, nil
+}
+
+// decodePointer decodes the pointer with the given control byte whose payload
+// starts at the given offset and returns the offset it points to and the
+// offset of the next value.
+func (d *mmdbDecoder) decodePointer(ctrl, offset uint64) (uint64, uint64, error) {
+	n := (ctrl>>3)&0x3 + 1
+	if offset+n > uint64(len(d.data)) {
+		return 0, 0, errors.New("unexpected end of data")
+	}
+
+	var pointer uint64
+	if n < 4 {
+		pointer = ctrl & 0x7
+	}
+	for _, c := range d.data[offset : offset+n] {
+		pointer = pointer<<8 | uint64(c)
+	}
+	pointer += [...]uint64{0, 2048, 526336, 0}[n-1]
+	return pointer, offset + n, nil
+}
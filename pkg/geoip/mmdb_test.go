@@ -0,0 +1,251 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"maps"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MMDB", func() {
+	Describe("#ParseCountriesMMDB", func() {
+		It("should parse and aggregate the networks of an IPv4 database", func() {
+			db := newTestMMDB(4, 24)
+			db.insert("10.0.0.0/24", map[string]any{"country": map[string]any{"iso_code": "DE"}})
+			db.insert("10.0.1.0/24", map[string]any{"country": map[string]any{"iso_code": "de"}})
+			db.insert("10.1.0.0/16", map[string]any{"country": map[string]any{"iso_code": "FR"}})
+			db.insert("10.2.0.0/16", map[string]any{"continent": map[string]any{"code": "EU"}})
+
+			countries, err := ParseCountriesMMDB(db.bytes())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(countries).To(Equal(map[string][]string{
+				"DE": {"10.0.0.0/23"},
+				"FR": {"10.1.0.0/16"},
+			}))
+		})
+
+		It("should return IPv4 networks of IPv6 databases as IPv4 CIDRs and skip the aliases", func() {
+			for _, recordSize := range []int{24, 28, 32} {
+				db := newTestMMDB(6, recordSize)
+				db.insert("::10.0.0.0/104", map[string]any{"country": map[string]any{"iso_code": "DE"}})
+				db.insert("2001:db8::/32", map[string]any{"country": map[string]any{"iso_code": "FR"}})
+				db.alias("::ffff:0:0/96", "::/96")
+				db.alias("2002::/16", "::/96")
+
+				countries, err := ParseCountriesMMDB(db.bytes())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(countries).To(Equal(map[string][]string{
+					"DE": {"10.0.0.0/8"},
+					"FR": {"2001:db8::/32"},
+				}), "record size %d", recordSize)
+			}
+		})
+
+		It("should fail for invalid country codes", func() {
+			db := newTestMMDB(4, 24)
+			db.insert("10.0.0.0/24", map[string]any{"country": map[string]any{"iso_code": "Germany"}})
+
+			_, err := ParseCountriesMMDB(db.bytes())
+			Expect(err).To(MatchError(`invalid country code "Germany"`))
+		})
+
+		It("should fail for files without metadata", func() {
+			_, err := ParseCountriesMMDB([]byte("10.0.0.0,10.0.0.255,DE\n"))
+			Expect(err).To(MatchError("invalid MMDB file: metadata not found"))
+		})
+	})
+
+	Describe("#ParseASNsMMDB", func() {
+		It("should parse the ASNs", func() {
+			db := newTestMMDB(4, 28)
+			db.insert("10.0.0.0/24", map[string]any{"autonomous_system_number": uint32(64496), "autonomous_system_organization": "Example Incorporated Networks Limited"})
+			db.insert("10.0.1.0/24", map[string]any{"autonomous_system_number": uint32(64496), "autonomous_system_organization": "Example Incorporated Networks Limited"})
+			db.insert("10.2.0.0/16", map[string]any{"autonomous_system_number": uint32(64497)})
+
+			asns, err := ParseASNsMMDB(db.bytes())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(asns).To(Equal(map[uint32][]string{
+				64496: {"10.0.0.0/23"},
+				64497: {"10.2.0.0/16"},
+			}))
+		})
+	})
+
+	Describe("#Load", func() {
+		It("should load MMDB files by their extension", func() {
+			db := newTestMMDB(4, 24)
+			db.insert("10.0.0.0/24", map[string]any{"country": map[string]any{"iso_code": "DE"}})
+			dir := GinkgoT().TempDir()
+			countriesFile := filepath.Join(dir, "countries.mmdb")
+			Expect(os.WriteFile(countriesFile, db.bytes(), 0o600)).To(Succeed())
+
+			loaded, err := Load(countriesFile, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Countries([]string{"DE"})).To(Equal(map[string][]string{"DE": {"10.0.0.0/24"}}))
+		})
+	})
+})
+
+// testMMDB builds MMDB files, see https://maxmind.github.io/MaxMind-DB/. Records
+// of the nodes are node indexes (>= 0), empty (-1) or data offsets (<= -2).
+type testMMDB struct {
+	ipVersion  int
+	recordSize int
+	nodes      [][2]int64
+	data       []byte
+	// strings are the offsets of the strings in the data section, repeated
+	// strings are written as pointers if it is set
+	strings map[string]int
+}
+
+func newTestMMDB(ipVersion, recordSize int) *testMMDB {
+	return &testMMDB{
+		ipVersion:  ipVersion,
+		recordSize: recordSize,
+		nodes:      [][2]int64{{-1, -1}},
+		strings:    map[string]int{},
+	}
+}
+
+// insert adds the given network with the given record.
+func (t *testMMDB) insert(cidr string, record map[string]any) {
+	offset := len(t.data)
+	t.data = t.encode(t.data, record)
+	node, bit := t.lastNode(cidr)
+	t.nodes[node][bit] = -2 - int64(offset)
+}
+
+// alias lets the given network point to the node of the target network.
+func (t *testMMDB) alias(cidr, target string) {
+	node, bit := t.lastNode(target)
+	targetNode := t.nodes[node][bit]
+	node, bit = t.lastNode(cidr)
+	t.nodes[node][bit] = targetNode
+}
+
+// lastNode returns the node and the bit of the record of the given network,
+// creating the nodes on the way.
+func (t *testMMDB) lastNode(cidr string) (int, int) {
+	prefix := netip.MustParsePrefix(cidr)
+	addr := prefix.Addr().AsSlice()
+
+	node := 0
+	for i := 0; ; i++ {
+		bit := int(addr[i/8]>>(7-i%8)) & 1
+		if i == prefix.Bits()-1 {
+			return node, bit
+		}
+		if t.nodes[node][bit] < 0 {
+			t.nodes = append(t.nodes, [2]int64{-1, -1})
+			t.nodes[node][bit] = int64(len(t.nodes) - 1)
+		}
+		node = int(t.nodes[node][bit])
+	}
+}
+
+func (t *testMMDB) bytes() []byte {
+	nodeCount := int64(len(t.nodes))
+	var out []byte
+	for _, node := range t.nodes {
+		var records [2]uint32
+		for i, record := range node {
+			switch {
+			case record >= 0:
+				records[i] = uint32(record)
+			case record == -1:
+				records[i] = uint32(nodeCount)
+			default:
+				records[i] = uint32(nodeCount + 16 + (-2 - record))
+			}
+		}
+		switch t.recordSize {
+		case 24:
+			out = append(out, byte(records[0]>>16), byte(records[0]>>8), byte(records[0]),
+				byte(records[1]>>16), byte(records[1]>>8), byte(records[1]))
+		case 28:
+			out = append(out, byte(records[0]>>16), byte(records[0]>>8), byte(records[0]),
+				byte(records[0]>>20&0xF0|records[1]>>24&0x0F),
+				byte(records[1]>>16), byte(records[1]>>8), byte(records[1]))
+		default:
+			out = binary.BigEndian.AppendUint32(out, records[0])
+			out = binary.BigEndian.AppendUint32(out, records[1])
+		}
+	}
+	out = append(out, make([]byte, 16)...)
+	out = append(out, t.data...)
+	out = append(out, mmdbMetadataMarker...)
+	// the metadata is not part of the data section, so it does not use
+	// pointers
+	metadata := &testMMDB{}
+	return metadata.encode(out, map[string]any{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint16(t.recordSize),
+		"ip_version":                  uint16(t.ipVersion),
+		"database_type":               "Test",
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+		"build_epoch":                 uint64(1700000000),
+	})
+}
+
+// encode appends the given value to out. Maps are written in a stable order.
+func (t *testMMDB) encode(out []byte, value any) []byte {
+	control := func(out []byte, typ, size int) []byte {
+		ctrl := byte(typ << 5)
+		if typ > 7 {
+			ctrl = 0
+		}
+		var extra []byte
+		switch {
+		case size < 29:
+			ctrl |= byte(size)
+		case size < 285:
+			ctrl |= 29
+			extra = []byte{byte(size - 29)}
+		default:
+			ctrl |= 30
+			extra = []byte{byte((size - 285) >> 8), byte(size - 285)}
+		}
+		out = append(out, ctrl)
+		if typ > 7 {
+			out = append(out, byte(typ-7))
+		}
+		return append(out, extra...)
+	}
+	switch v := value.(type) {
+	case string:
+		// out is the data section, so its length is the offset of the string
+		if offset, ok := t.strings[v]; ok {
+			return append(out, byte(mmdbPointer<<5|offset>>8&0x7), byte(offset))
+		}
+		if t.strings != nil {
+			t.strings[v] = len(out)
+		}
+		out = control(out, mmdbString, len(v))
+		return append(out, v...)
+	case uint16:
+		out = control(out, mmdbUint16, 2)
+		return binary.BigEndian.AppendUint16(out, v)
+	case uint32:
+		out = control(out, mmdbUint32, 4)
+		return binary.BigEndian.AppendUint32(out, v)
+	case uint64:
+		out = control(out, mmdbUint64, 8)
+		return binary.BigEndian.AppendUint64(out, v)
+	case map[string]any:
+		out = control(out, mmdbMap, len(v))
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			out = t.encode(out, key)
+			out = t.encode(out, v[key])
+		}
+		return out
+	default:
+		Fail("unsupported type")
+		return nil
+	}
+}
@@ -0,0 +1,14 @@
+package geoip
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestGeoIP(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "geoip Test Suite")
+}
@@ -0,0 +1,48 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetProjectForNamespace returns the given namespace of the garden cluster and
+// the Project it belongs to. Objects which do not exist are returned as nil.
+func GetProjectForNamespace(
+	ctx context.Context,
+	c client.Reader,
+	namespace string,
+) (*corev1.Namespace, *gardencorev1beta1.Project, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("error getting namespace %s: %w", namespace, err)
+	}
+
+	projectName, ok := ns.Labels[v1beta1constants.ProjectName]
+	if !ok {
+		return ns, nil, nil
+	}
+	project := &gardencorev1beta1.Project{}
+	if err := c.Get(ctx, client.ObjectKey{Name: projectName}, project); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ns, nil, nil
+		}
+		return nil, nil, fmt.Errorf("error getting project %s: %w", projectName, err)
+	}
+	return ns, project, nil
+}
+
+// HasProjectLabel checks whether the given namespace or Project has the given
+// label set to "true". Both may be nil.
+func HasProjectLabel(ns *corev1.Namespace, project *gardencorev1beta1.Project, label string) bool {
+	return (ns != nil && ns.Labels[label] == "true") ||
+		(project != nil && project.Labels[label] == "true")
+}
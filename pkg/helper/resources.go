@@ -0,0 +1,68 @@
+package helper
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	aclhelper "github.com/stackitcloud/gardener-extension-acl/pkg/apis/acl/helper"
+)
+
+// GetReferencedCIDRs reads the CIDRs stored with the given key in the Secret or
+// ConfigMap of the given kind, namespace and name, see GetReferencedData and
+// ParseReferencedCIDRs.
+func GetReferencedCIDRs(ctx context.Context, c client.Reader, kind, namespace, name, key string) ([]string, error) {
+	data, err := GetReferencedData(ctx, c, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	cidrs, err := ParseReferencedCIDRs(data, key)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s/%s: %w", kind, namespace, name, err)
+	}
+	return cidrs, nil
+}
+
+// GetReferencedData returns the data of the Secret or ConfigMap of the given
+// kind, namespace and name. Errors getting the resource are wrapped, so they
+// can be checked with the functions of the apierrors package.
+func GetReferencedData(ctx context.Context, c client.Reader, kind, namespace, name string) (map[string]string, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+
+	switch kind {
+	case "Secret":
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("error getting Secret %s: %w", key, err)
+		}
+		data := make(map[string]string, len(secret.Data))
+		for k, v := range secret.Data {
+			data[k] = string(v)
+		}
+		return data, nil
+	case "ConfigMap":
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(ctx, key, configMap); err != nil {
+			return nil, fmt.Errorf("error getting ConfigMap %s: %w", key, err)
+		}
+		return configMap.Data, nil
+	default:
+		return nil, fmt.Errorf("unsupported kind %s of %s, must be Secret or ConfigMap", kind, key)
+	}
+}
+
+// ParseReferencedCIDRs parses the CIDRs stored with the given key in the given
+// data, see aclhelper.ParseCIDRList.
+func ParseReferencedCIDRs(data map[string]string, key string) ([]string, error) {
+	value, ok := data[key]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", key)
+	}
+	cidrs, err := aclhelper.ParseCIDRList(value)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", key, err)
+	}
+	return cidrs, nil
+}
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scrapeTimeout is the timeout for listing and scraping all targets during a
+// single collection.
+const scrapeTimeout = 10 * time.Second
+
+var deniedDesc = prometheus.NewDesc(
+	"acl_denied_total",
+	"Number of connections denied by the ACL of an endpoint of a shoot.",
+	[]string{"shoot", "endpoint"},
+	nil,
+)
+
+// Target is an Envoy serving its stats in the Prometheus format to scrape.
+type Target struct {
+	// Pod is the name of the pod running Envoy.
+	Pod string
+	// URL is the base URL of the stats, e.g. `http://10.0.0.1:15090`.
+	URL string
+}
+
+// TargetsFunc returns the Envoys to scrape.
+type TargetsFunc func(ctx context.Context) ([]Target, error)
+
+// IngressGatewayTargets returns a TargetsFunc listing all running
+// istio-ingressgateway pods with their stats on the given port, which is 15090
+// for Istio, as the admin interface of Envoy only listens on localhost. As the
+// pods are listed on every collection, c should not be backed by a cache.
+func IngressGatewayTargets(c client.Reader, port int) TargetsFunc {
+	return func(ctx context.Context) ([]Target, error) {
+		podList := &corev1.PodList{}
+		if err := c.List(ctx, podList, client.MatchingLabels{
+			v1beta1constants.LabelApp: v1beta1constants.DefaultIngressGatewayAppLabelValue,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to list istio-ingressgateway pods: %w", err)
+		}
+
+		var targets []Target
+		for _, pod := range podList.Items {
+			if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+				continue
+			}
+			targets = append(targets, Target{
+				Pod: pod.Name,
+				URL: "http://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(port)),
+			})
+		}
+		return targets, nil
+	}
+}
+
+// LeaderOnly returns a TargetsFunc returning the targets of the given
+// TargetsFunc only once elected is closed, i.e. the replica is elected as
+// leader, see manager.Manager.Elected. Otherwise, every replica of the
+// extension would expose the same counters.
+func LeaderOnly(elected <-chan struct{}, targets TargetsFunc) TargetsFunc {
+	return func(ctx context.Context) ([]Target, error) {
+		select {
+		case <-elected:
+			return targets(ctx)
+		default:
+			return nil, nil
+		}
+	}
+}
+
+// Exporter is a prometheus.Collector exposing the connections denied by the
+// ACLs as acl_denied_total. On every collection, it scrapes the stats of all
+// targets and sums them up per shoot and endpoint. The sum decreases whenever a
+// pod goes away or cannot be scraped, which Prometheus treats as a counter
+// reset. Targets which cannot be scraped are skipped.
+type Exporter struct {
+	targets TargetsFunc
+	client  *http.Client
+	log     logr.Logger
+}
+
+var _ prometheus.Collector = &Exporter{}
+
+// NewExporter returns an Exporter scraping the given targets.
+func NewExporter(targets TargetsFunc, log logr.Logger) *Exporter {
+	return &Exporter{
+		targets: targets,
+		client:  &http.Client{},
+		log:     log,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- deniedDesc
+}
+
+// Collect implements prometheus.Collector.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), scrapeTimeout)
+	defer cancel()
+
+	targets, err := e.targets(ctx)
+	if err != nil {
+		e.log.Error(err, "Failed to list the Envoys to scrape")
+		return
+	}
+
+	denied := map[DeniedKey]float64{}
+	for _, target := range targets {
+		stats, err := e.scrape(ctx, target.URL)
+		if err != nil {
+			e.log.Error(err, "Failed to scrape Envoy stats", "pod", target.Pod, "target", target.URL)
+			continue
+		}
+		for key, count := range stats {
+			denied[key] += count
+		}
+	}
+
+	for key, count := range denied {
+		ch <- prometheus.MustNewConstMetric(deniedDesc, prometheus.CounterValue, count, key.Shoot, key.Endpoint)
+	}
+}
+
+// scrape fetches the stats of the extension in the Prometheus format from the
+// Envoy at the given base URL, see ParseDeniedStats.
+func (e *Exporter) scrape(ctx context.Context, target string) (map[DeniedKey]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target+"/stats/prometheus?filter="+url.QueryEscape("acl_"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ParseDeniedStats(resp.Body)
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("exporter", func() {
+	// newEnvoy returns a stub of Envoy serving the given stats in the Prometheus
+	// format.
+	newEnvoy := func(stats string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/stats/prometheus" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, stats)
+		}))
+		DeferCleanup(server.Close)
+		return server
+	}
+
+	staticTargets := func(targets ...Target) TargetsFunc {
+		return func(context.Context) ([]Target, error) {
+			return targets, nil
+		}
+	}
+
+	Describe("#Collect", func() {
+		It("should sum up the denied connections of all targets", func() {
+			envoy1 := newEnvoy(`envoy_acl_bar__foo_api__rbac_denied{} 3
+envoy_http_outbound_0_0_0_0_8132_rbac_acl_bar__foo_vpn_denied{} 4
+`)
+			envoy2 := newEnvoy(`envoy_acl_bar__foo_api__rbac_denied{} 2
+`)
+			exporter := NewExporter(staticTargets(
+				Target{Pod: "gateway-1", URL: envoy1.URL},
+				Target{Pod: "gateway-2", URL: envoy2.URL},
+			), GinkgoLogr)
+
+			Expect(testutil.CollectAndCompare(exporter, strings.NewReader(`
+# HELP acl_denied_total Number of connections denied by the ACL of an endpoint of a shoot.
+# TYPE acl_denied_total counter
+acl_denied_total{endpoint="api",shoot="bar--foo"} 5
+acl_denied_total{endpoint="vpn",shoot="bar--foo"} 4
+`))).To(Succeed())
+		})
+
+		It("should skip targets which cannot be scraped", func() {
+			envoy := newEnvoy("envoy_acl_bar__foo_api__rbac_denied{} 3\n")
+			broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			DeferCleanup(broken.Close)
+			exporter := NewExporter(staticTargets(
+				Target{Pod: "gateway-1", URL: broken.URL},
+				Target{Pod: "gateway-2", URL: envoy.URL},
+			), GinkgoLogr)
+
+			Expect(testutil.CollectAndCompare(exporter, strings.NewReader(`
+# HELP acl_denied_total Number of connections denied by the ACL of an endpoint of a shoot.
+# TYPE acl_denied_total counter
+acl_denied_total{endpoint="api",shoot="bar--foo"} 3
+`))).To(Succeed())
+		})
+	})
+
+	Describe("#LeaderOnly", func() {
+		It("should only return the targets once elected", func() {
+			elected := make(chan struct{})
+			targets := LeaderOnly(elected, staticTargets(Target{Pod: "gateway-1", URL: "http://10.0.0.1:15090"}))
+
+			Expect(targets(context.Background())).To(BeEmpty())
+			close(elected)
+			Expect(targets(context.Background())).To(ConsistOf(Target{Pod: "gateway-1", URL: "http://10.0.0.1:15090"}))
+		})
+	})
+
+	Describe("#IngressGatewayTargets", func() {
+		It("should return the running istio-ingressgateway pods", func() {
+			newPod := func(name string, labels map[string]string, phase corev1.PodPhase, podIP string) *corev1.Pod {
+				return &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-ingress", Labels: labels},
+					Status:     corev1.PodStatus{Phase: phase, PodIP: podIP},
+				}
+			}
+			gatewayLabels := map[string]string{"app": "istio-ingressgateway"}
+			c := fakeclient.NewClientBuilder().WithObjects(
+				newPod("gateway-1", gatewayLabels, corev1.PodRunning, "10.0.0.1"),
+				newPod("gateway-2", gatewayLabels, corev1.PodPending, ""),
+				newPod("gateway-3", gatewayLabels, corev1.PodRunning, "fd00::1"),
+				newPod("other", map[string]string{"app": "other"}, corev1.PodRunning, "10.0.0.2"),
+			).Build()
+
+			targets, err := IngressGatewayTargets(c, 15090)(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(targets).To(ConsistOf(
+				Target{Pod: "gateway-1", URL: "http://10.0.0.1:15090"},
+				Target{Pod: "gateway-3", URL: "http://[fd00::1]:15090"},
+			))
+		})
+	})
+})
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// deniedStatPattern matches the counters of the connections denied by the RBAC
+// filters of the extension in the Prometheus format of Envoy, whose stats are
+// prefixed per shoot and endpoint, e.g. `acl_<shortID>_api_`. Envoy prefixes
+// the names with `envoy_` and replaces all characters but letters, digits and
+// underscores with underscores. Network filters prefix their stats with it
+// (`acl_<shortID>_api_.rbac.denied`), HTTP filters append it to the stats
+// prefix of the connection manager (`http.<prefix>.rbac.acl_<shortID>_vpn_denied`).
+// The counters of shadow rules are not matched.
+var deniedStatPattern = regexp.MustCompile(`^envoy_(?:http_(?:.*?_)?rbac_)?acl_([a-z0-9_]+)_(api|vpn|httpproxy|ingress)_(?:_?rbac_)?denied$`)
+
+// DeniedKey identifies the denied connections of an endpoint of a shoot.
+type DeniedKey struct {
+	// Shoot is the short ID of the shoot, i.e. its technical ID without the
+	// `shoot--` prefix.
+	Shoot string
+	// Endpoint is the endpoint of the shoot, i.e. `api`, `vpn`, `httpproxy` or
+	// `ingress`.
+	Endpoint string
+}
+
+// ParseDeniedStats parses the output of the `/stats/prometheus` endpoint of
+// Envoy, i.e. metrics in the Prometheus text format, and returns the number of
+// denied connections per shoot and endpoint. All other metrics are ignored.
+// The stats prefixes must not be extracted into tags, which is the case for the
+// stats tags configured by Istio.
+func ParseDeniedStats(r io.Reader) (map[DeniedKey]float64, error) {
+	denied := map[DeniedKey]float64{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, err := parseSample(line)
+		if err != nil {
+			return nil, err
+		}
+		match := deniedStatPattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		count, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of metric %s: %w", name, err)
+		}
+		// short shoot IDs only contain lowercase letters, digits and dashes,
+		// so the underscores replaced dashes
+		shoot := strings.ReplaceAll(match[1], "_", "-")
+		denied[DeniedKey{Shoot: shoot, Endpoint: match[2]}] += count
+	}
+
+	return denied, scanner.Err()
+}
+
+// parseSample returns the name and the value of the given sample in the
+// Prometheus text format, e.g. `name{label="value"} 42`. The labels are
+// skipped.
+func parseSample(line string) (string, string, error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end < 0 {
+		return "", "", fmt.Errorf("invalid sample %q", line)
+	}
+	name, rest := line[:end], line[end:]
+
+	if strings.HasPrefix(rest, "{") {
+		// label values are quoted and may contain escaped quotes and braces
+		quoted := false
+		end = -1
+		for i := 1; i < len(rest) && end < 0; i++ {
+			switch {
+			case quoted && rest[i] == '\\':
+				i++
+			case rest[i] == '"':
+				quoted = !quoted
+			case !quoted && rest[i] == '}':
+				end = i
+			}
+		}
+		if end < 0 {
+			return "", "", fmt.Errorf("invalid labels of metric %s", name)
+		}
+		rest = rest[end+1:]
+	}
+
+	// the value may be followed by a timestamp
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", "", fmt.Errorf("missing value of metric %s", name)
+	}
+	return name, fields[0], nil
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("stats", func() {
+	Describe("#ParseDeniedStats", func() {
+		It("should parse the denied connections of network and HTTP filters", func() {
+			denied, err := ParseDeniedStats(strings.NewReader(`# TYPE envoy_acl_bar__foo_api__rbac_allowed counter
+envoy_acl_bar__foo_api__rbac_allowed{} 12
+# TYPE envoy_acl_bar__foo_api__rbac_denied counter
+envoy_acl_bar__foo_api__rbac_denied{} 3
+envoy_acl_bar__foo_ingress_rbac_denied 1
+envoy_http_outbound_0_0_0_0_8132_rbac_acl_bar__foo_vpn_allowed{} 7
+envoy_http_outbound_0_0_0_0_8132_rbac_acl_bar__foo_vpn_denied{} 4
+envoy_http_rbac_acl_baz__qux_httpproxy_denied{http_conn_manager_prefix="0.0.0.0_8443"} 2
+envoy_http_rbac_acl_baz__qux_httpproxy_denied{http_conn_manager_prefix="0.0.0.0_9443"} 1
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(denied).To(Equal(map[DeniedKey]float64{
+				{Shoot: "bar--foo", Endpoint: "api"}:       3,
+				{Shoot: "bar--foo", Endpoint: "ingress"}:   1,
+				{Shoot: "bar--foo", Endpoint: "vpn"}:       4,
+				{Shoot: "baz--qux", Endpoint: "httpproxy"}: 3,
+			}))
+		})
+
+		It("should ignore shadow rules and other metrics", func() {
+			denied, err := ParseDeniedStats(strings.NewReader(`envoy_acl_bar__foo_api__rbac_acl_bar__foo_api_shadow_shadow_denied{} 5
+envoy_http_outbound_0_0_0_0_8132_rbac_acl_bar__foo_vpn_shadow_shadow_denied{} 6
+envoy_envoyrbac_rbac_denied{} 7
+envoy_cluster_upstream_cx_total{cluster_name="outbound|443||kubernetes.default.svc.cluster.local"} 8
+envoy_listener_manager_lds_update_time_bucket{le="0.5"} 0
+envoy_server_labels{label="a \"quoted} value"} 1
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(denied).To(BeEmpty())
+		})
+
+		It("should fail on invalid values", func() {
+			_, err := ParseDeniedStats(strings.NewReader("envoy_acl_bar__foo_api__rbac_denied{} many\n"))
+			Expect(err).To(MatchError(ContainSubstring("invalid value of metric envoy_acl_bar__foo_api__rbac_denied")))
+		})
+
+		It("should fail on invalid samples", func() {
+			_, err := ParseDeniedStats(strings.NewReader(`envoy_acl_bar__foo_api__rbac_denied{label="value} 3` + "\n"))
+			Expect(err).To(MatchError("invalid labels of metric envoy_acl_bar__foo_api__rbac_denied"))
+		})
+	})
+})
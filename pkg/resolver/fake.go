@@ -0,0 +1,56 @@
+package resolver
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sync"
+)
+
+// Fake is a Resolver returning the addresses configured with SetHost, e.g. for
+// tests. Hosts without addresses are not found.
+type Fake struct {
+	mu    sync.RWMutex
+	hosts map[string][]netip.Addr
+}
+
+// NewFake returns a new Fake resolving the given hosts to the given addresses.
+func NewFake(hosts map[string][]string) *Fake {
+	f := &Fake{}
+	for host, addrs := range hosts {
+		f.SetHost(host, addrs...)
+	}
+	return f
+}
+
+// SetHost sets the addresses of the given host. Without addresses, the host is
+// not found anymore.
+func (f *Fake) SetHost(host string, addrs ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.hosts == nil {
+		f.hosts = map[string][]netip.Addr{}
+	}
+	if len(addrs) == 0 {
+		delete(f.hosts, host)
+		return
+	}
+	parsed := make([]netip.Addr, 0, len(addrs))
+	for _, addr := range addrs {
+		parsed = append(parsed, netip.MustParseAddr(addr))
+	}
+	f.hosts[host] = parsed
+}
+
+// LookupNetIP implements Resolver.
+func (f *Fake) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	addrs, ok := f.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
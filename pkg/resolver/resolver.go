@@ -0,0 +1,52 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+)
+
+// Resolver resolves host names to IP addresses. It is implemented by
+// *net.Resolver, e.g. net.DefaultResolver, and by Fake for tests.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// LookupHosts resolves the given hosts and returns the CIDRs of their IPv4 and
+// IPv6 addresses, i.e. /32 and /128 prefixes, by host. The CIDRs of a host are
+// sorted and unique. Hosts which cannot be resolved or have no addresses are
+// omitted from the result and reported in the returned error.
+func LookupHosts(ctx context.Context, r Resolver, hosts []string) (map[string][]string, error) {
+	var (
+		hostCIDRs = make(map[string][]string, len(hosts))
+		errs      []error
+	)
+	for _, host := range hosts {
+		addrs, err := r.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error resolving host %s: %w", host, err))
+			continue
+		}
+		if len(addrs) == 0 {
+			errs = append(errs, fmt.Errorf("host %s has no addresses", host))
+			continue
+		}
+
+		prefixes := make([]netip.Prefix, 0, len(addrs))
+		for _, addr := range addrs {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+		slices.SortFunc(prefixes, func(a, b netip.Prefix) int { return a.Addr().Compare(b.Addr()) })
+		prefixes = slices.Compact(prefixes)
+
+		cidrs := make([]string, 0, len(prefixes))
+		for _, prefix := range prefixes {
+			cidrs = append(cidrs, prefix.String())
+		}
+		hostCIDRs[host] = cidrs
+	}
+	return hostCIDRs, errors.Join(errs...)
+}